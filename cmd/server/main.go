@@ -7,8 +7,10 @@ import (
 	"os"
 	"time"
 
+	"backend-work-mate/internal/auth"
 	"backend-work-mate/internal/config"
 	_ "backend-work-mate/internal/docs"
+	"backend-work-mate/internal/mail"
 	"backend-work-mate/internal/server"
 	"backend-work-mate/internal/storage/postgres"
 
@@ -44,7 +46,17 @@ func main() {
 		log.Fatalf("failed to run migrations: %v", err)
 	}
 
-	r := server.NewRouter(dbpool, cfg)
+	mailer, err := mail.New(cfg)
+	if err != nil {
+		log.Fatalf("failed to init mailer: %v", err)
+	}
+
+	keys, err := auth.LoadKeySet(cfg)
+	if err != nil {
+		log.Fatalf("failed to load jwt keys: %v", err)
+	}
+
+	r := server.NewRouter(dbpool, cfg, mailer, keys)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
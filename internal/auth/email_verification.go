@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"backend-work-mate/internal/mail"
+	"backend-work-mate/internal/storage/postgres"
+)
+
+var ErrInvalidVerificationToken = errors.New("token verifikasi tidak valid atau sudah kedaluwarsa")
+
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+func (s *Service) sendVerificationEmail(ctx context.Context, user *postgres.User) error {
+	token, err := s.createUserToken(ctx, user.ID, postgres.TokenPurposeEmailVerification, s.verifyTTL)
+	if err != nil {
+		return err
+	}
+	link := s.appBaseURL + "/verify-email?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email Workmate",
+		Body: fmt.Sprintf("Halo %s,\n\nTerima kasih sudah mendaftar di Workmate.\n"+
+			"Buka link berikut untuk memverifikasi email kamu (berlaku %s):\n\n%s\n", user.Name, s.verifyTTL, link),
+	})
+}
+
+// VerifyEmail menandai email user terverifikasi memakai token dari email.
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	t, err := s.userTokens.Consume(ctx, postgres.TokenPurposeEmailVerification, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return ErrInvalidVerificationToken
+	}
+	return s.users.MarkEmailVerified(ctx, t.UserID)
+}
+
+// ResendVerification mengirim ulang email verifikasi bila akun ada dan belum terverifikasi.
+// Seperti ForgotPassword, hasilnya tidak membedakan email terdaftar atau tidak.
+func (s *Service) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive || user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.sendVerificationEmail(ctx, user)
+}
@@ -0,0 +1,406 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend-work-mate/internal/config"
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Fake repository in-memory untuk unit test Service. Interface di-embed agar
+// method yang tidak dipakai test cukup panic bila terpanggil.
+
+type fakeUsers struct {
+	postgres.UserRepository
+	byID map[string]*postgres.User
+}
+
+func (f *fakeUsers) GetByEmail(_ context.Context, email string) (*postgres.User, error) {
+	for _, u := range f.byID {
+		if strings.EqualFold(u.Email, email) {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeUsers) GetByID(_ context.Context, id string) (*postgres.User, error) {
+	return f.byID[id], nil
+}
+
+func (f *fakeUsers) Create(_ context.Context, u *postgres.User) error {
+	u.ID = fmt.Sprintf("user-%d", len(f.byID)+1)
+	u.IsActive = true
+	f.byID[u.ID] = u
+	return nil
+}
+
+func (f *fakeUsers) UpdatePassword(_ context.Context, id, passwordHash string) error {
+	f.byID[id].PasswordHash = passwordHash
+	return nil
+}
+
+func (f *fakeUsers) IncrementTokenVersion(_ context.Context, id string) error {
+	f.byID[id].TokenVersion++
+	return nil
+}
+
+func (f *fakeUsers) MarkEmailVerified(_ context.Context, id string) error {
+	now := time.Now()
+	f.byID[id].EmailVerifiedAt = &now
+	return nil
+}
+
+type fakeMFA struct {
+	postgres.MFARepository
+	byUser map[string]*postgres.UserMFA
+}
+
+func (f *fakeMFA) Get(_ context.Context, userID string) (*postgres.UserMFA, error) {
+	return f.byUser[userID], nil
+}
+
+// UseStep meniru update bersyarat last_used_step < step di Postgres.
+func (f *fakeMFA) UseStep(_ context.Context, userID string, step int64) (bool, error) {
+	m := f.byUser[userID]
+	if m == nil || m.LastUsedStep >= step {
+		return false, nil
+	}
+	m.LastUsedStep = step
+	return true, nil
+}
+
+func (f *fakeMFA) UseRecoveryCode(context.Context, string, string) (bool, error) {
+	return false, nil
+}
+
+type fakeRevokedTokens struct {
+	postgres.RevokedTokenRepository
+	jtis map[string]bool
+}
+
+func (f *fakeRevokedTokens) Revoke(_ context.Context, jti string, _ time.Time) error {
+	f.jtis[jti] = true
+	return nil
+}
+
+func (f *fakeRevokedTokens) IsRevoked(_ context.Context, jti string) (bool, error) {
+	return f.jtis[jti], nil
+}
+
+type fakeLoginAttempts struct {
+	attempts map[string]*postgres.LoginAttempt
+}
+
+func (f *fakeLoginAttempts) Get(_ context.Context, key string) (*postgres.LoginAttempt, error) {
+	return f.attempts[key], nil
+}
+
+// RecordFailure meniru hitungan yang dimulai ulang setelah window, dihitung sejak
+// kegagalan terakhir atau berakhirnya kunci.
+func (f *fakeLoginAttempts) RecordFailure(_ context.Context, key string, window time.Duration) (*postgres.LoginAttempt, error) {
+	now := time.Now()
+	a := f.attempts[key]
+	if a == nil {
+		a = &postgres.LoginAttempt{Key: key}
+		f.attempts[key] = a
+	}
+	last := a.LastFailureAt
+	if a.LockedUntil != nil && a.LockedUntil.After(last) {
+		last = *a.LockedUntil
+	}
+	if a.Failures > 0 && last.Before(now.Add(-window)) {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailureAt = now
+	return a, nil
+}
+
+func (f *fakeLoginAttempts) Lock(_ context.Context, key string, until time.Time) error {
+	if a := f.attempts[key]; a != nil {
+		a.LockedUntil = &until
+	}
+	return nil
+}
+
+func (f *fakeLoginAttempts) Reset(_ context.Context, key string) error {
+	delete(f.attempts, key)
+	return nil
+}
+
+type fakeSessions struct {
+	postgres.SessionRepository
+	mu   sync.Mutex
+	byID map[string]*postgres.Session
+}
+
+func (f *fakeSessions) Create(_ context.Context, s *postgres.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s.ID = fmt.Sprintf("session-%d", len(f.byID)+1)
+	s.CreatedAt = time.Now()
+	s.LastSeenAt = s.CreatedAt
+	cp := *s
+	f.byID[s.ID] = &cp
+	return nil
+}
+
+func (f *fakeSessions) GetByID(_ context.Context, id string) (*postgres.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.byID[id]
+	if s == nil {
+		return nil, nil
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (f *fakeSessions) Extend(_ context.Context, id string, expiresAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.byID[id].ExpiresAt = expiresAt
+	return nil
+}
+
+func (f *fakeSessions) Revoke(_ context.Context, userID, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.byID[id]
+	if s == nil || s.UserID != userID || s.RevokedAt != nil {
+		return pgx.ErrNoRows
+	}
+	now := time.Now()
+	s.RevokedAt = &now
+	return nil
+}
+
+func (f *fakeSessions) RevokeAllForUser(_ context.Context, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	for _, s := range f.byID {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// fakeRefreshTokens mengembalikan salinan agar perubahan (used_at, revoked_at)
+// hanya terlihat lewat pembacaan berikutnya, seperti baris di Postgres.
+// afterGet (bila diisi) dipanggil setelah GetByHash untuk menyisipkan request lain.
+type fakeRefreshTokens struct {
+	postgres.RefreshTokenRepository
+	mu       sync.Mutex
+	byHash   map[string]*postgres.RefreshToken
+	afterGet func()
+}
+
+func (f *fakeRefreshTokens) Create(_ context.Context, t *postgres.RefreshToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t.ID = fmt.Sprintf("refresh-%d", len(f.byHash)+1)
+	t.CreatedAt = time.Now()
+	cp := *t
+	f.byHash[t.TokenHash] = &cp
+	return nil
+}
+
+func (f *fakeRefreshTokens) GetByHash(_ context.Context, tokenHash string) (*postgres.RefreshToken, error) {
+	f.mu.Lock()
+	t := f.byHash[tokenHash]
+	var cp *postgres.RefreshToken
+	if t != nil {
+		c := *t
+		cp = &c
+	}
+	hook := f.afterGet
+	f.mu.Unlock()
+	if hook != nil {
+		hook()
+	}
+	return cp, nil
+}
+
+// MarkUsed meniru update bersyarat used_at is null di Postgres.
+func (f *fakeRefreshTokens) MarkUsed(_ context.Context, id string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, t := range f.byHash {
+		if t.ID == id && t.UsedAt == nil {
+			now := time.Now()
+			t.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRefreshTokens) RevokeFamily(_ context.Context, familyID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	for _, t := range f.byHash {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (f *fakeRefreshTokens) RevokeAllForUser(_ context.Context, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	for _, t := range f.byHash {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+type fakeUserTokens struct {
+	postgres.UserTokenRepository
+	byHash map[string]*postgres.UserToken
+}
+
+func (f *fakeUserTokens) Create(_ context.Context, t *postgres.UserToken) error {
+	t.ID = fmt.Sprintf("token-%d", len(f.byHash)+1)
+	t.CreatedAt = time.Now()
+	f.byHash[t.TokenHash] = t
+	return nil
+}
+
+func (f *fakeUserTokens) DeleteForUser(_ context.Context, userID, purpose string) error {
+	for hash, t := range f.byHash {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			delete(f.byHash, hash)
+		}
+	}
+	return nil
+}
+
+func (f *fakeUserTokens) Get(_ context.Context, purpose, tokenHash string) (*postgres.UserToken, error) {
+	t := f.byHash[tokenHash]
+	if t == nil || t.Purpose != purpose || t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+		return nil, nil
+	}
+	return t, nil
+}
+
+func (f *fakeUserTokens) Consume(ctx context.Context, purpose, tokenHash string) (*postgres.UserToken, error) {
+	t, err := f.Get(ctx, purpose, tokenHash)
+	if t != nil {
+		now := time.Now()
+		t.UsedAt = &now
+	}
+	return t, err
+}
+
+type fakeOIDCStates struct {
+	byHash map[string]*postgres.OIDCState
+}
+
+func (f *fakeOIDCStates) Create(_ context.Context, s *postgres.OIDCState) error {
+	f.byHash[s.StateHash] = s
+	return nil
+}
+
+// Consume meniru delete ... returning yang hanya mengembalikan state belum kedaluwarsa.
+func (f *fakeOIDCStates) Consume(_ context.Context, stateHash string) (*postgres.OIDCState, error) {
+	s := f.byHash[stateHash]
+	delete(f.byHash, stateHash)
+	if s == nil || time.Now().After(s.ExpiresAt) {
+		return nil, nil
+	}
+	return s, nil
+}
+
+// testEnv adalah Service uji beserta fake repository-nya.
+type testEnv struct {
+	svc           *Service
+	users         *fakeUsers
+	mfa           *fakeMFA
+	attempts      *fakeLoginAttempts
+	sessions      *fakeSessions
+	refreshTokens *fakeRefreshTokens
+	userTokens    *fakeUserTokens
+	oidcStates    *fakeOIDCStates
+}
+
+// newTestEnv membuat Service dengan fake repository dan konfigurasi uji (bcrypt
+// cost minimum agar test cepat); edit (boleh nil) dapat mengubah konfigurasi.
+func newTestEnv(t *testing.T, edit func(*config.Config)) *testEnv {
+	t.Helper()
+	cfg := &config.Config{
+		JWTSecret:                "test-secret",
+		AccessTokenTTL:           15 * time.Minute,
+		RefreshTokenTTL:          24 * time.Hour,
+		PasswordHashAlgorithm:    "bcrypt",
+		BcryptCost:               4,
+		LoginMaxFailuresPerEmail: 3,
+		LoginMaxFailuresPerIP:    5,
+		LoginLockoutBase:         time.Minute,
+		LoginLockoutMax:          time.Hour,
+		LoginFailureWindow:       15 * time.Minute,
+		PasswordMinLength:        8,
+		PasswordRejectCommon:     true,
+		PasswordResetTTL:         time.Hour,
+	}
+	if edit != nil {
+		edit(cfg)
+	}
+	keys, err := LoadKeySet(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := &testEnv{
+		users:         &fakeUsers{byID: map[string]*postgres.User{}},
+		mfa:           &fakeMFA{byUser: map[string]*postgres.UserMFA{}},
+		attempts:      &fakeLoginAttempts{attempts: map[string]*postgres.LoginAttempt{}},
+		sessions:      &fakeSessions{byID: map[string]*postgres.Session{}},
+		refreshTokens: &fakeRefreshTokens{byHash: map[string]*postgres.RefreshToken{}},
+		userTokens:    &fakeUserTokens{byHash: map[string]*postgres.UserToken{}},
+		oidcStates:    &fakeOIDCStates{byHash: map[string]*postgres.OIDCState{}},
+	}
+	env.svc = NewService(Store{
+		Users:         env.users,
+		RefreshTokens: env.refreshTokens,
+		UserTokens:    env.userTokens,
+		RevokedTokens: &fakeRevokedTokens{jtis: map[string]bool{}},
+		MFA:           env.mfa,
+		LoginAttempts: env.attempts,
+		OIDCStates:    env.oidcStates,
+		Sessions:      env.sessions,
+	}, nil, keys, cfg)
+	return env
+}
+
+// testService adalah newTestEnv dengan konfigurasi bawaan.
+func testService(t *testing.T) (*Service, *fakeUsers, *fakeMFA, *fakeLoginAttempts) {
+	t.Helper()
+	env := newTestEnv(t, nil)
+	return env.svc, env.users, env.mfa, env.attempts
+}
+
+// addUser menyimpan user aktif dengan password yang sudah di-hash.
+func addUser(t *testing.T, svc *Service, users *fakeUsers, id, email, password string) *postgres.User {
+	t.Helper()
+	hash, err := svc.hasher.hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &postgres.User{ID: id, Email: email, PasswordHash: hash, Role: postgres.RoleEmployee, IsActive: true}
+	users.byID[id] = u
+	return u
+}
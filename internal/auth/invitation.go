@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"backend-work-mate/internal/mail"
+	"backend-work-mate/internal/storage/postgres"
+)
+
+var ErrInvalidInvitation = errors.New("undangan tidak valid, sudah dipakai atau kedaluwarsa")
+
+type CreateInvitationInput struct {
+	Email      string  `json:"email" binding:"required,email"`
+	Role       string  `json:"role" binding:"omitempty,oneof=Admin Employee"`
+	Department *string `json:"department"`
+}
+
+type AcceptInvitationInput struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// CreateInvitation membuat undangan baru dan mengirim link-nya ke email tujuan.
+// Undangan lama untuk email yang sama dibatalkan.
+func (s *Service) CreateInvitation(ctx context.Context, invitedBy string, in CreateInvitationInput) (*postgres.Invitation, error) {
+	existing, err := s.users.GetByEmail(ctx, in.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmailTaken
+	}
+	role := postgres.RoleEmployee
+	if in.Role != "" {
+		role = postgres.UserRole(in.Role)
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.invitations.RevokePendingForEmail(ctx, in.Email); err != nil {
+		return nil, err
+	}
+	inv := &postgres.Invitation{
+		Email:      in.Email,
+		Role:       role,
+		Department: in.Department,
+		TokenHash:  hashToken(token),
+		InvitedBy:  &invitedBy,
+		ExpiresAt:  time.Now().Add(s.inviteTTL),
+	}
+	if err := s.invitations.Create(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	link := s.appBaseURL + "/accept-invitation?token=" + url.QueryEscape(token)
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      inv.Email,
+		Subject: "Undangan bergabung ke Workmate",
+		Body: fmt.Sprintf("Halo,\n\nKamu diundang untuk bergabung ke Workmate.\n"+
+			"Buka link berikut untuk membuat akun (berlaku %s):\n\n%s\n", s.inviteTTL, link),
+	}); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (s *Service) ListInvitations(ctx context.Context, pendingOnly bool, limit, offset int) ([]postgres.Invitation, int, error) {
+	return s.invitations.List(ctx, pendingOnly, limit, offset)
+}
+
+// RevokeInvitation membatalkan undangan yang belum diterima.
+func (s *Service) RevokeInvitation(ctx context.Context, id string) error {
+	return s.invitations.Revoke(ctx, id)
+}
+
+// AcceptInvitation membuat akun dari undangan lalu langsung membuka session.
+// Email dianggap terverifikasi karena token diterima lewat email tersebut.
+func (s *Service) AcceptInvitation(ctx context.Context, in AcceptInvitationInput, client ClientInfo) (*AuthToken, error) {
+	tokenHash := hashToken(in.Token)
+	inv, err := s.invitations.GetByHash(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, ErrInvalidInvitation
+	}
+	existing, err := s.users.GetByEmail(ctx, inv.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmailTaken
+	}
+
+	if err := s.policy.Validate(in.Password, inv.Email); err != nil {
+		return nil, err
+	}
+	hash, err := s.hasher.hash(in.Password)
+	if err != nil {
+		return nil, err
+	}
+	if inv, err = s.invitations.Accept(ctx, tokenHash); err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, ErrInvalidInvitation
+	}
+	user := &postgres.User{
+		Name:         in.Name,
+		Email:        inv.Email,
+		PasswordHash: hash,
+		Role:         inv.Role,
+		Department:   inv.Department,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.users.MarkEmailVerified(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return s.startSession(ctx, user, client, false)
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// purposeMFA menandai token tantangan MFA yang hanya bisa ditukar di /api/login/mfa.
+const purposeMFA = "mfa"
+
+const mfaTokenTTL = 5 * time.Minute
+
+// Claims adalah isi access token Workmate. "ver" harus sama dengan token_version
+// milik user; menaikkan versi tersebut membatalkan semua token yang sudah terbit.
+type Claims struct {
+	Email   string            `json:"email"`
+	Role    postgres.UserRole `json:"role"`
+	Version int               `json:"ver"`
+	// MFA bernilai true bila sesi ini lolos verifikasi TOTP/recovery code.
+	MFA bool `json:"mfa,omitempty"`
+	// Purpose kosong untuk access token biasa.
+	Purpose string `json:"purpose,omitempty"`
+	// SessionID adalah session login asal token; session yang dicabut membatalkan token.
+	SessionID string `json:"sid,omitempty"`
+	// Scopes dan PersonalAccessTokenID hanya terisi untuk request yang memakai PAT.
+	Scopes                []string `json:"-"`
+	PersonalAccessTokenID string   `json:"-"`
+	jwt.RegisteredClaims
+}
+
+func (s *Service) signAccessToken(user *postgres.User, now time.Time, sessionID string, mfa bool) (string, error) {
+	return s.signClaims(user, now, s.accessTTL, sessionID, mfa, "")
+}
+
+func (s *Service) signMFAToken(user *postgres.User, now time.Time) (string, error) {
+	return s.signClaims(user, now, mfaTokenTTL, "", false, purposeMFA)
+}
+
+func (s *Service) signClaims(user *postgres.User, now time.Time, ttl time.Duration, sessionID string, mfa bool, purpose string) (string, error) {
+	jti, err := newUUID()
+	if err != nil {
+		return "", err
+	}
+	claims := Claims{
+		Email:     user.Email,
+		Role:      user.Role,
+		Version:   user.TokenVersion,
+		MFA:       mfa,
+		Purpose:   purpose,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return s.keys.sign(claims)
+}
+
+// ParseAndValidateJWT memverifikasi access token dengan key sesuai kid di header
+// dan mengembalikan claims-nya. Pengecekan pencabutan (denylist/token version)
+// dilakukan oleh Service.Authenticate.
+func ParseAndValidateJWT(tokenString string, keys *KeySet) (*Claims, error) {
+	claims, err := parseClaims(tokenString, keys)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("invalid token purpose")
+	}
+	return claims, nil
+}
+
+func parseClaims(tokenString string, keys *KeySet) (*Claims, error) {
+	var claims Claims
+	parsed, err := jwt.ParseWithClaims(tokenString, &claims, keys.keyfunc)
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.Subject == "" || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil, errors.New("invalid claims")
+	}
+	return &claims, nil
+}
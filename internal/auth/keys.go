@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"backend-work-mate/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySet berisi key penanda tangan JWT. Token baru ditandatangani dengan key aktif;
+// semua key yang terdaftar tetap dipakai untuk verifikasi sehingga key bisa dirotasi
+// tanpa memutus sesi: tambahkan key baru, jadikan aktif, lalu hapus key lama
+// setelah token lama kedaluwarsa.
+type KeySet struct {
+	active *jwtKey
+	keys   map[string]*jwtKey
+	// legacy adalah secret HS256 untuk token tanpa kid (mode HS256 atau masa transisi).
+	legacy []byte
+}
+
+type jwtKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+// LoadKeySet membaca key dari JWT_PRIVATE_KEY_FILES / JWT_PUBLIC_KEY_FILES.
+// Tanpa private key, token ditandatangani HS256 dengan JWT_SECRET seperti sebelumnya.
+func LoadKeySet(cfg *config.Config) (*KeySet, error) {
+	ks := &KeySet{keys: map[string]*jwtKey{}}
+	if len(cfg.JWTPrivateKeyFiles) == 0 {
+		ks.legacy = []byte(cfg.JWTSecret)
+		return ks, nil
+	}
+	if cfg.JWTAcceptLegacyHS256 {
+		ks.legacy = []byte(cfg.JWTSecret)
+	}
+
+	for kid, path := range cfg.JWTPrivateKeyFiles {
+		block, err := readPEM(path)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+		}
+		key, err := parsePrivateKey(kid, block)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+		}
+		ks.keys[kid] = key
+	}
+	for kid, path := range cfg.JWTPublicKeyFiles {
+		if _, ok := ks.keys[kid]; ok {
+			return nil, fmt.Errorf("jwt key %s: kid terdaftar dua kali", kid)
+		}
+		block, err := readPEM(path)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+		}
+		key, err := parsePublicKey(kid, block)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+		}
+		ks.keys[kid] = key
+	}
+
+	active, ok := ks.keys[cfg.JWTActiveKID]
+	if !ok || active.private == nil {
+		return nil, fmt.Errorf("JWT_ACTIVE_KID %q harus menunjuk ke salah satu JWT_PRIVATE_KEY_FILES", cfg.JWTActiveKID)
+	}
+	ks.active = active
+	return ks, nil
+}
+
+// sign menandatangani claims dengan key aktif (kid diset di header).
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	if ks.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.legacy)
+	}
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	token.Header["kid"] = ks.active.kid
+	return token.SignedString(ks.active.private)
+}
+
+// keyfunc memilih key verifikasi berdasarkan kid dan memastikan algoritma token
+// sesuai dengan jenis key tersebut.
+func (ks *KeySet) keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok && len(ks.legacy) > 0 {
+			return ks.legacy, nil
+		}
+		return nil, errors.New("invalid signing method")
+	}
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("invalid signing method")
+	}
+	return key.public, nil
+}
+
+// JWK adalah representasi public key dalam JSON Web Key Set.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS mengembalikan semua public key verifikasi; kosong pada mode HS256.
+func (ks *KeySet) JWKS() []JWK {
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	out := []JWK{}
+	for _, kid := range kids {
+		key := ks.keys[kid]
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			out = append(out, JWK{
+				Kty: "RSA", Kid: kid, Use: "sig", Alg: key.method.Alg(),
+				N: base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			out = append(out, JWK{
+				Kty: "OKP", Kid: kid, Use: "sig", Alg: key.method.Alg(),
+				Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return out
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("file bukan PEM")
+	}
+	return block, nil
+}
+
+func parsePrivateKey(kid string, block *pem.Block) (*jwtKey, error) {
+	var priv any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("tipe PEM tidak didukung: %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, errors.New("key RSA minimal 2048 bit")
+		}
+		return &jwtKey{kid: kid, method: jwt.SigningMethodRS256, private: k, public: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &jwtKey{kid: kid, method: jwt.SigningMethodEdDSA, private: k, public: k.Public()}, nil
+	default:
+		return nil, errors.New("hanya key RSA (RS256) dan Ed25519 (EdDSA) yang didukung")
+	}
+}
+
+func parsePublicKey(kid string, block *pem.Block) (*jwtKey, error) {
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("tipe PEM tidak didukung: %s", block.Type)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return &jwtKey{kid: kid, method: jwt.SigningMethodRS256, public: k}, nil
+	case ed25519.PublicKey:
+		return &jwtKey{kid: kid, method: jwt.SigningMethodEdDSA, public: k}, nil
+	default:
+		return nil, errors.New("hanya key RSA (RS256) dan Ed25519 (EdDSA) yang didukung")
+	}
+}
+
+// JWKS mengembalikan public key verifikasi access token untuk /.well-known/jwks.json.
+func (s *Service) JWKS() []JWK {
+	return s.keys.JWKS()
+}
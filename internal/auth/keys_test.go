@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"backend-work-mate/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeys berisi key uji: "rsa1" (PKCS#1) dan "ed1" (PKCS#8) dengan private key,
+// serta "old" yang hanya berupa public key RSA (key lama setelah rotasi).
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ed      ed25519.PrivateKey
+	old     *rsa.PrivateKey
+	rsaPub  []byte
+	private map[string]string
+	public  map[string]string
+	dir     string
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+	k := &testKeys{dir: t.TempDir(), private: map[string]string{}, public: map[string]string{}}
+	var err error
+	if k.rsa, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+		t.Fatal(err)
+	}
+	if k.old, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+		t.Fatal(err)
+	}
+	if _, k.ed, err = ed25519.GenerateKey(rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	edDER, err := x509.MarshalPKCS8PrivateKey(k.ed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldDER, err := x509.MarshalPKIXPublicKey(&k.old.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPubDER, err := x509.MarshalPKIXPublicKey(&k.rsa.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k.rsaPub = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPubDER})
+	k.private["rsa1"] = k.write(t, "rsa1.pem", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k.rsa)})
+	k.private["ed1"] = k.write(t, "ed1.pem", &pem.Block{Type: "PRIVATE KEY", Bytes: edDER})
+	k.public["old"] = k.write(t, "old.pem", &pem.Block{Type: "PUBLIC KEY", Bytes: oldDER})
+	return k
+}
+
+func (k *testKeys) write(t *testing.T, name string, block *pem.Block) string {
+	t.Helper()
+	path := filepath.Join(k.dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func (k *testKeys) keySet(t *testing.T, activeKID string, acceptLegacy bool) *KeySet {
+	t.Helper()
+	ks, err := LoadKeySet(&config.Config{
+		JWTSecret:            "test-secret",
+		JWTPrivateKeyFiles:   k.private,
+		JWTPublicKeyFiles:    k.public,
+		JWTActiveKID:         activeKID,
+		JWTAcceptLegacyHS256: acceptLegacy,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ks
+}
+
+func testClaims() Claims {
+	now := time.Now()
+	return Claims{RegisteredClaims: jwt.RegisteredClaims{
+		ID:        "jti-1",
+		Subject:   "u1",
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+	}}
+}
+
+// signWith menandatangani testClaims dengan method dan key bebas; kid kosong
+// berarti header tanpa kid.
+func signWith(t *testing.T, method jwt.SigningMethod, key any, kid string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, testClaims())
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestKeySetSignsWithActiveKey(t *testing.T) {
+	k := newTestKeys(t)
+	for kid, alg := range map[string]string{"rsa1": "RS256", "ed1": "EdDSA"} {
+		signer := k.keySet(t, kid, false)
+		signed, err := signer.sign(testClaims())
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, _, err := jwt.NewParser().ParseUnverified(signed, &Claims{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Header["kid"] != kid || parsed.Method.Alg() != alg {
+			t.Errorf("aktif %s: header kid=%v alg=%s, ingin %s", kid, parsed.Header["kid"], parsed.Method.Alg(), alg)
+		}
+		// key set lain dengan key aktif berbeda tetap bisa memverifikasi lewat kid
+		verifier := k.keySet(t, "rsa1", false)
+		if kid == "rsa1" {
+			verifier = k.keySet(t, "ed1", false)
+		}
+		if _, err := parseClaims(signed, verifier); err != nil {
+			t.Errorf("aktif %s: verifikasi gagal: %v", kid, err)
+		}
+	}
+}
+
+func TestKeySetVerifiesRotatedPublicKey(t *testing.T) {
+	k := newTestKeys(t)
+	ks := k.keySet(t, "rsa1", false)
+	if _, err := parseClaims(signWith(t, jwt.SigningMethodRS256, k.old, "old"), ks); err != nil {
+		t.Fatalf("token key lama ditolak: %v", err)
+	}
+}
+
+func TestKeySetRejects(t *testing.T) {
+	k := newTestKeys(t)
+	ks := k.keySet(t, "rsa1", false)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"kid tidak dikenal", signWith(t, jwt.SigningMethodRS256, other, "nope"), "unknown kid"},
+		{"key lain dengan kid terdaftar", signWith(t, jwt.SigningMethodRS256, other, "rsa1"), "verification error"},
+		{"alg tidak sesuai kid", signWith(t, jwt.SigningMethodEdDSA, k.ed, "rsa1"), "invalid signing method"},
+		// alg confusion: HS256 dengan public key RSA sebagai secret HMAC
+		{"HS256 dengan public key, ber-kid", signWith(t, jwt.SigningMethodHS256, k.rsaPub, "rsa1"), "invalid signing method"},
+		{"HS256 dengan public key, tanpa kid", signWith(t, jwt.SigningMethodHS256, k.rsaPub, ""), "invalid signing method"},
+		{"HS256 legacy saat tidak diterima", signWith(t, jwt.SigningMethodHS256, []byte("test-secret"), ""), "invalid signing method"},
+		{"RS256 tanpa kid", signWith(t, jwt.SigningMethodRS256, k.rsa, ""), "invalid signing method"},
+	}
+	for _, tt := range tests {
+		_, err := parseClaims(tt.token, ks)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, ingin mengandung %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestKeySetLegacyHS256(t *testing.T) {
+	k := newTestKeys(t)
+	ks := k.keySet(t, "rsa1", true)
+	if _, err := parseClaims(signWith(t, jwt.SigningMethodHS256, []byte("test-secret"), ""), ks); err != nil {
+		t.Fatalf("token HS256 lama ditolak selama masa transisi: %v", err)
+	}
+	if _, err := parseClaims(signWith(t, jwt.SigningMethodHS256, k.rsaPub, ""), ks); err == nil {
+		t.Fatal("HS256 dengan public key RSA diterima")
+	}
+	signed, err := ks.sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed, _, err := jwt.NewParser().ParseUnverified(signed, &Claims{}); err != nil || parsed.Method.Alg() != "RS256" {
+		t.Fatalf("token baru harus tetap RS256: %v", err)
+	}
+
+	// tanpa private key, KeySet bekerja penuh dengan HS256
+	hs, err := LoadKeySet(&config.Config{JWTSecret: "test-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err = hs.sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseClaims(signed, hs); err != nil {
+		t.Fatalf("mode HS256: %v", err)
+	}
+	if got := hs.JWKS(); len(got) != 0 {
+		t.Fatalf("JWKS mode HS256 = %v, ingin kosong", got)
+	}
+}
+
+func TestKeySetJWKSHasNoPrivateMaterial(t *testing.T) {
+	k := newTestKeys(t)
+	ks := k.keySet(t, "rsa1", true)
+	jwks := ks.JWKS()
+
+	raw, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var generic []map[string]any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		t.Fatal(err)
+	}
+	for _, jwk := range generic {
+		for _, field := range []string{"d", "p", "q", "dp", "dq", "qi", "k"} {
+			if _, ok := jwk[field]; ok {
+				t.Errorf("JWK %v berisi field private %q", jwk["kid"], field)
+			}
+		}
+	}
+
+	if len(jwks) != 3 || jwks[0].Kid != "ed1" || jwks[1].Kid != "old" || jwks[2].Kid != "rsa1" {
+		t.Fatalf("JWKS = %+v, ingin ed1, old, rsa1", jwks)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	if ed := jwks[0]; ed.Kty != "OKP" || ed.Alg != "EdDSA" || ed.X != b64(k.ed.Public().(ed25519.PublicKey)) {
+		t.Errorf("JWK ed1 = %+v", ed)
+	}
+	if r := jwks[2]; r.Kty != "RSA" || r.Alg != "RS256" || r.N != b64(k.rsa.N.Bytes()) || r.E != "AQAB" {
+		t.Errorf("JWK rsa1 = %+v", r)
+	}
+}
+
+func TestLoadKeySetRequiresActivePrivateKey(t *testing.T) {
+	k := newTestKeys(t)
+	for _, kid := range []string{"", "old", "nope"} {
+		_, err := LoadKeySet(&config.Config{
+			JWTPrivateKeyFiles: k.private,
+			JWTPublicKeyFiles:  k.public,
+			JWTActiveKID:       kid,
+		})
+		if err == nil {
+			t.Errorf("JWT_ACTIVE_KID %q diterima", kid)
+		}
+	}
+}
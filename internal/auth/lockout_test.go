@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend-work-mate/internal/storage/postgres"
+)
+
+func TestLockoutPolicyDuration(t *testing.T) {
+	p := lockoutPolicy{base: time.Minute, max: time.Hour}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Minute},
+		{4, 2 * time.Minute},
+		{5, 4 * time.Minute},
+		{8, 32 * time.Minute},
+		{9, time.Hour},
+		{1000, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := p.duration(tt.failures, 3); got != tt.want {
+			t.Errorf("duration(%d, 3) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+// lockoutUser menyiapkan user dengan MFA aktif agar login berhasil berhenti di
+// tantangan MFA tanpa membutuhkan repository session.
+func lockoutUser(t *testing.T) (*Service, *postgres.User, *fakeLoginAttempts) {
+	t.Helper()
+	svc, users, mfa, attempts := testService(t)
+	user := addUser(t, svc, users, "u1", "user@example.com", "Secret123!")
+	enabled := time.Now()
+	mfa.byUser[user.ID] = &postgres.UserMFA{UserID: user.ID, Secret: testTOTPSecret, EnabledAt: &enabled}
+	return svc, user, attempts
+}
+
+func TestLoginLocksAfterThreshold(t *testing.T) {
+	svc, user, attempts := lockoutUser(t)
+	ctx := context.Background()
+	client := ClientInfo{IP: "10.0.0.1"}
+	wrong := LoginInput{Email: user.Email, Password: "salah"}
+
+	for i := 1; i <= svc.lockout.maxPerEmail; i++ {
+		_, err := svc.Login(ctx, wrong, client)
+		var locked *LockedError
+		if err == nil || errors.As(err, &locked) {
+			t.Fatalf("percobaan %d: err = %v, want password salah", i, err)
+		}
+		a := attempts.attempts[emailLockKey(user.Email)]
+		if i < svc.lockout.maxPerEmail && a.LockedUntil != nil {
+			t.Fatalf("percobaan %d: terkunci sebelum batas", i)
+		}
+	}
+	_, err := svc.Login(ctx, LoginInput{Email: user.Email, Password: "Secret123!"}, client)
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("setelah batas: err = %v, want *LockedError", err)
+	}
+	if locked.RetryAfter <= 0 || locked.RetryAfter > svc.lockout.base {
+		t.Errorf("RetryAfter = %v, want (0, %v]", locked.RetryAfter, svc.lockout.base)
+	}
+}
+
+func TestLoginUnknownEmailCountsAsFailure(t *testing.T) {
+	svc, _, attempts := lockoutUser(t)
+	_, err := svc.Login(context.Background(), LoginInput{Email: "ghost@example.com", Password: "Secret123!"}, ClientInfo{IP: "10.0.0.1"})
+	if err == nil {
+		t.Fatal("login dengan email tidak terdaftar berhasil")
+	}
+	if a := attempts.attempts[emailLockKey("ghost@example.com")]; a == nil || a.Failures != 1 {
+		t.Fatalf("kegagalan tidak tercatat: %+v", a)
+	}
+}
+
+func TestLoginAllowedAfterLockExpires(t *testing.T) {
+	svc, user, attempts := lockoutUser(t)
+	expired := time.Now().Add(-time.Second)
+	attempts.attempts[emailLockKey(user.Email)] = &postgres.LoginAttempt{
+		Key:           emailLockKey(user.Email),
+		Failures:      svc.lockout.maxPerEmail,
+		LockedUntil:   &expired,
+		LastFailureAt: time.Now().Add(-svc.lockout.base),
+	}
+	res, err := svc.Login(context.Background(), LoginInput{Email: user.Email, Password: "Secret123!"}, ClientInfo{IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if !res.MFARequired {
+		t.Fatal("login tidak berlanjut ke tantangan MFA")
+	}
+}
+
+func TestLoginSuccessResetsEmailFailures(t *testing.T) {
+	svc, user, attempts := lockoutUser(t)
+	ctx := context.Background()
+	client := ClientInfo{IP: "10.0.0.1"}
+	for i := 0; i < svc.lockout.maxPerEmail-1; i++ {
+		if _, err := svc.Login(ctx, LoginInput{Email: user.Email, Password: "salah"}, client); err == nil {
+			t.Fatal("login dengan password salah berhasil")
+		}
+	}
+	if _, err := svc.Login(ctx, LoginInput{Email: user.Email, Password: "Secret123!"}, client); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if a := attempts.attempts[emailLockKey(user.Email)]; a != nil {
+		t.Errorf("hitungan email tidak di-reset: %+v", a)
+	}
+	if a := attempts.attempts[ipLockKey(client.IP)]; a == nil || a.Failures != svc.lockout.maxPerEmail-1 {
+		t.Errorf("hitungan IP seharusnya tetap: %+v", a)
+	}
+}
+
+func TestLoginDisabledAccountKeepsFailures(t *testing.T) {
+	svc, user, attempts := lockoutUser(t)
+	ctx := context.Background()
+	client := ClientInfo{IP: "10.0.0.1"}
+	if _, err := svc.Login(ctx, LoginInput{Email: user.Email, Password: "salah"}, client); err == nil {
+		t.Fatal("login dengan password salah berhasil")
+	}
+	user.IsActive = false
+	if _, err := svc.Login(ctx, LoginInput{Email: user.Email, Password: "Secret123!"}, client); !errors.Is(err, ErrAccountDisabled) {
+		t.Fatalf("err = %v, want ErrAccountDisabled", err)
+	}
+	if a := attempts.attempts[emailLockKey(user.Email)]; a == nil || a.Failures != 1 {
+		t.Errorf("hitungan email ikut di-reset untuk akun nonaktif: %+v", a)
+	}
+}
+
+func TestLoginFailuresResetAfterWindow(t *testing.T) {
+	svc, user, attempts := lockoutUser(t)
+	key := emailLockKey(user.Email)
+	// kegagalan lama di luar window (tetapi masih di bawah LoginLockoutMax)
+	attempts.attempts[key] = &postgres.LoginAttempt{
+		Key:           key,
+		Failures:      svc.lockout.maxPerEmail - 1,
+		LastFailureAt: time.Now().Add(-svc.lockout.window - time.Minute),
+	}
+	if _, err := svc.Login(context.Background(), LoginInput{Email: user.Email, Password: "salah"}, ClientInfo{}); err == nil {
+		t.Fatal("login dengan password salah berhasil")
+	}
+	if a := attempts.attempts[key]; a.Failures != 1 || a.LockedUntil != nil {
+		t.Fatalf("hitungan tidak dimulai ulang setelah window: %+v", a)
+	}
+}
+
+func TestLoginLockoutEscalatesPastWindow(t *testing.T) {
+	svc, user, attempts := lockoutUser(t)
+	key := emailLockKey(user.Email)
+	// kunci 32 menit (lebih panjang dari window) baru saja berakhir
+	expired := time.Now().Add(-time.Second)
+	attempts.attempts[key] = &postgres.LoginAttempt{
+		Key:           key,
+		Failures:      svc.lockout.maxPerEmail + 5,
+		LockedUntil:   &expired,
+		LastFailureAt: time.Now().Add(-32 * time.Minute),
+	}
+	if _, err := svc.Login(context.Background(), LoginInput{Email: user.Email, Password: "salah"}, ClientInfo{}); err == nil {
+		t.Fatal("login dengan password salah berhasil")
+	}
+	a := attempts.attempts[key]
+	if a.Failures != svc.lockout.maxPerEmail+6 || a.LockedUntil == nil || time.Until(*a.LockedUntil) < 59*time.Minute {
+		t.Fatalf("kunci tidak berlanjut berlipat: %+v", a)
+	}
+}
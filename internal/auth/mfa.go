@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"backend-work-mate/internal/storage/postgres"
+)
+
+var (
+	ErrInvalidMFACode    = errors.New("kode MFA tidak valid")
+	ErrInvalidMFAToken   = errors.New("token MFA tidak valid atau sudah kedaluwarsa")
+	ErrMFAAlreadyEnabled = errors.New("MFA sudah aktif")
+	ErrMFANotEnabled     = errors.New("MFA belum aktif")
+	ErrMFANotEnrolled    = errors.New("MFA belum didaftarkan, panggil enroll terlebih dahulu")
+)
+
+const recoveryCodeCount = 10
+
+// MFAVerifyInput menukar token tantangan MFA dengan token sesi. Isi salah satu
+// dari Code (TOTP) atau RecoveryCode.
+type MFAVerifyInput struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// MFACodeInput berisi kode TOTP; untuk disable, recovery code juga diterima.
+type MFACodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFAStatus struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+func (s *Service) MFAStatus(ctx context.Context, userID string) (*MFAStatus, error) {
+	m, err := s.mfa.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || m.EnabledAt == nil {
+		return &MFAStatus{}, nil
+	}
+	left, err := s.mfa.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAStatus{Enabled: true, RecoveryCodesLeft: left}, nil
+}
+
+// EnrollMFA membuat secret TOTP baru (belum aktif) beserta URI otpauth:// untuk QR code.
+func (s *Service) EnrollMFA(ctx context.Context, claims *Claims) (*MFAEnrollment, error) {
+	m, err := s.mfa.Get(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if m != nil && m.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.SavePending(ctx, claims.Subject, secret); err != nil {
+		return nil, err
+	}
+	return &MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(s.mfaIssuer, claims.Email, secret),
+	}, nil
+}
+
+// ConfirmMFA mengaktifkan MFA setelah user membuktikan aplikasi authenticator-nya
+// menghasilkan kode yang benar, lalu mengembalikan recovery code (hanya ditampilkan sekali).
+func (s *Service) ConfirmMFA(ctx context.Context, userID, code string) ([]string, error) {
+	m, err := s.mfa.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if m.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if err := s.verifyTOTP(ctx, m, code); err != nil {
+		return nil, err
+	}
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.Enable(ctx, userID); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableMFA menonaktifkan MFA setelah verifikasi kode TOTP atau recovery code.
+func (s *Service) DisableMFA(ctx context.Context, userID, code string) error {
+	m, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyMFACode(ctx, m, code, code); err != nil {
+		return err
+	}
+	return s.mfa.Delete(ctx, userID)
+}
+
+// RegenerateRecoveryCodes mengganti seluruh recovery code setelah verifikasi kode TOTP.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	m, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyTOTP(ctx, m, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+// VerifyMFA adalah langkah kedua login: token tantangan dari Login ditukar dengan
+// access/refresh token bila kode TOTP atau recovery code valid.
+func (s *Service) VerifyMFA(ctx context.Context, in MFAVerifyInput, client ClientInfo) (*AuthToken, error) {
+	claims, err := parseClaims(in.MFAToken, s.keys)
+	if err != nil || claims.Purpose != purposeMFA {
+		return nil, ErrInvalidMFAToken
+	}
+	used, err := s.revokedTokens.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if used {
+		return nil, ErrInvalidMFAToken
+	}
+	user, err := s.users.GetByID(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.TokenVersion != claims.Version {
+		return nil, ErrInvalidMFAToken
+	}
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	if err := s.checkLockout(ctx, user.Email, client.IP); err != nil {
+		return nil, err
+	}
+	m, err := s.enabledMFA(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyMFACode(ctx, m, in.Code, in.RecoveryCode); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			if err := s.recordLoginFailure(ctx, user.Email, client.IP); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	// token tantangan hanya boleh ditukar sekali
+	if err := s.revokedTokens.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+	return s.startSession(ctx, user, client, true)
+}
+
+func (s *Service) enabledMFA(ctx context.Context, userID string) (*postgres.UserMFA, error) {
+	m, err := s.mfa.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || m.EnabledAt == nil {
+		return nil, ErrMFANotEnabled
+	}
+	return m, nil
+}
+
+// verifyMFACode menerima kode TOTP bila ada, selain itu mencoba recovery code.
+func (s *Service) verifyMFACode(ctx context.Context, m *postgres.UserMFA, code, recoveryCode string) error {
+	if code != "" {
+		if err := s.verifyTOTP(ctx, m, code); err == nil || recoveryCode == "" {
+			return err
+		}
+	}
+	if recoveryCode == "" {
+		return ErrInvalidMFACode
+	}
+	ok, err := s.mfa.UseRecoveryCode(ctx, m.UserID, hashToken(normalizeRecoveryCode(recoveryCode)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *Service) verifyTOTP(ctx context.Context, m *postgres.UserMFA, code string) error {
+	step, ok := validateTOTP(m.Secret, code, time.Now())
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	fresh, err := s.mfa.UseStep(ctx, m.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *Service) replaceRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashToken(normalizeRecoveryCode(code))
+	}
+	if err := s.mfa.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// recoveryAlphabet menghindari karakter yang mudah tertukar (0/o, 1/l/i).
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// newRecoveryCode membuat kode berformat "xxxxx-xxxxx".
+func newRecoveryCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(recoveryAlphabet)))
+	for i := 0; i < 10; i++ {
+		if i == 5 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(recoveryAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
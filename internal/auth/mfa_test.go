@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend-work-mate/internal/storage/postgres"
+)
+
+func TestVerifyMFARejectsInvalidChallenge(t *testing.T) {
+	svc, users, mfa, _ := testService(t)
+	user := addUser(t, svc, users, "u1", "user@example.com", "Secret123!")
+	enabled := time.Now()
+	mfa.byUser[user.ID] = &postgres.UserMFA{UserID: user.ID, Secret: testTOTPSecret, EnabledAt: &enabled}
+	now := time.Now()
+
+	expired, err := svc.signMFAToken(user, now.Add(-2*mfaTokenTTL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	access, err := svc.signAccessToken(user, now, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	used, err := svc.signMFAToken(user, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := parseClaims(used, svc.keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.revokedTokens.Revoke(context.Background(), claims.ID, claims.ExpiresAt.Time); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"kedaluwarsa", expired},
+		{"access token biasa", access},
+		{"sudah ditukar", used},
+		{"bukan jwt", "not-a-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.VerifyMFA(context.Background(), MFAVerifyInput{MFAToken: tt.token, Code: "000000"}, ClientInfo{IP: "127.0.0.1"})
+			if !errors.Is(err, ErrInvalidMFAToken) {
+				t.Fatalf("err = %v, want ErrInvalidMFAToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyMFARejectsChallengeAfterTokenVersionBump(t *testing.T) {
+	svc, users, mfa, _ := testService(t)
+	user := addUser(t, svc, users, "u1", "user@example.com", "Secret123!")
+	enabled := time.Now()
+	mfa.byUser[user.ID] = &postgres.UserMFA{UserID: user.ID, Secret: testTOTPSecret, EnabledAt: &enabled}
+
+	token, err := svc.signMFAToken(user, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.TokenVersion++
+	_, err = svc.VerifyMFA(context.Background(), MFAVerifyInput{MFAToken: token, Code: "000000"}, ClientInfo{IP: "127.0.0.1"})
+	if !errors.Is(err, ErrInvalidMFAToken) {
+		t.Fatalf("err = %v, want ErrInvalidMFAToken", err)
+	}
+}
+
+func TestVerifyMFAWrongCodeCountsAsFailure(t *testing.T) {
+	svc, users, mfa, attempts := testService(t)
+	user := addUser(t, svc, users, "u1", "user@example.com", "Secret123!")
+	enabled := time.Now()
+	mfa.byUser[user.ID] = &postgres.UserMFA{UserID: user.ID, Secret: testTOTPSecret, EnabledAt: &enabled}
+
+	token, err := svc.signMFAToken(user, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := totpCode(testTOTPSecret, time.Now().Unix()/totpPeriod+5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = svc.VerifyMFA(context.Background(), MFAVerifyInput{MFAToken: token, Code: code}, ClientInfo{IP: "127.0.0.1"})
+	if !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("err = %v, want ErrInvalidMFACode", err)
+	}
+	if a := attempts.attempts[emailLockKey(user.Email)]; a == nil || a.Failures != 1 {
+		t.Fatalf("kegagalan tidak tercatat: %+v", a)
+	}
+}
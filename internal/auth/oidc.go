@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"backend-work-mate/internal/oidc"
+	"backend-work-mate/internal/storage/postgres"
+)
+
+var (
+	ErrOIDCDisabled        = errors.New("login SSO tidak dikonfigurasi")
+	ErrInvalidOIDCState    = errors.New("state login SSO tidak valid atau sudah kedaluwarsa")
+	ErrOIDCNoAccount       = errors.New("akun dengan email ini belum terdaftar di Workmate")
+	ErrOIDCEmailUnverified = errors.New("email di IdP belum diverifikasi")
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+// unusablePasswordHash dipakai untuk user hasil provisioning SSO; bukan hash bcrypt
+// yang valid sehingga login dengan password selalu gagal sampai user reset password.
+const unusablePasswordHash = "!oidc"
+
+func (s *Service) OIDCEnabled() bool {
+	return s.oidc != nil
+}
+
+// StartOIDCLogin menyimpan state, nonce dan PKCE verifier lalu mengembalikan URL
+// authorization endpoint IdP tujuan redirect.
+func (s *Service) StartOIDCLogin(ctx context.Context) (string, error) {
+	if s.oidc == nil {
+		return "", ErrOIDCDisabled
+	}
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return "", err
+	}
+	if err := s.oidcStates.Create(ctx, &postgres.OIDCState{
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	}); err != nil {
+		return "", err
+	}
+	return s.oidc.AuthCodeURL(ctx, state, nonce, challenge)
+}
+
+// CompleteOIDCLogin menukar authorization code, memverifikasi ID token, lalu
+// mencari (atau membuat) user berdasarkan email dan menerbitkan token Workmate.
+func (s *Service) CompleteOIDCLogin(ctx context.Context, code, state string, client ClientInfo) (*AuthToken, error) {
+	if s.oidc == nil {
+		return nil, ErrOIDCDisabled
+	}
+	st, err := s.oidcStates.Consume(ctx, hashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		return nil, ErrInvalidOIDCState
+	}
+	tokens, err := s.oidc.Exchange(ctx, code, st.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := s.oidc.VerifyIDToken(ctx, tokens.IDToken, st.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Email == "" {
+		return nil, errors.New("IdP tidak mengirim email")
+	}
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return nil, ErrOIDCEmailUnverified
+	}
+	verified := claims.EmailVerified != nil && *claims.EmailVerified
+
+	user, err := s.users.GetByEmail(ctx, claims.Email)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case user != nil && !verified && !(s.oidcAllowUnverified && user.PasswordHash == unusablePasswordHash):
+		// tanpa email_verified=true, IdP bisa saja mengizinkan email milik orang lain;
+		// akun yang sudah ada (mis. akun password) tidak boleh diambil alih. Hanya
+		// akun hasil provisioning SSO yang boleh masuk lagi tanpa claim tersebut.
+		return nil, ErrOIDCEmailUnverified
+	case user == nil:
+		if !s.oidcAutoProvision {
+			return nil, ErrOIDCNoAccount
+		}
+		if !verified && !s.oidcAllowUnverified {
+			return nil, ErrOIDCEmailUnverified
+		}
+		if user, err = s.provisionOIDCUser(ctx, claims); err != nil {
+			return nil, err
+		}
+	}
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	if verified && user.EmailVerifiedAt == nil {
+		if err := s.users.MarkEmailVerified(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	return s.completeLogin(ctx, user, client)
+}
+
+func (s *Service) provisionOIDCUser(ctx context.Context, claims *oidc.IDTokenClaims) (*postgres.User, error) {
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+	user := &postgres.User{
+		Name:         name,
+		Email:        claims.Email,
+		PasswordHash: unusablePasswordHash,
+		Role:         postgres.RoleEmployee,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
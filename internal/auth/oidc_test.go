@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend-work-mate/internal/config"
+	"backend-work-mate/internal/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// oidcEnv membuat Service yang terhubung ke mock IdP lokal.
+func oidcEnv(t *testing.T, autoProvision bool) (*testEnv, *oidctest.Server) {
+	t.Helper()
+	idp := oidctest.NewServer(t, "workmate", "secret")
+	env := newTestEnv(t, func(cfg *config.Config) {
+		cfg.OIDCIssuer = idp.URL
+		cfg.OIDCClientID = idp.ClientID
+		cfg.OIDCClientSecret = idp.ClientSecret
+		cfg.OIDCRedirectURL = "https://workmate.test/auth/oidc/callback"
+		cfg.OIDCAutoProvision = autoProvision
+		cfg.OIDCHTTPClient = idp.Client()
+	})
+	return env, idp
+}
+
+// startOIDC menjalankan StartOIDCLogin lalu login di mock IdP.
+func startOIDC(t *testing.T, env *testEnv, idp *oidctest.Server, edit func(jwt.MapClaims)) (code, state string) {
+	t.Helper()
+	authURL, err := env.svc.StartOIDCLogin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idp.Authorize(t, authURL, edit)
+}
+
+func TestCompleteOIDCLoginRejectsReusedState(t *testing.T) {
+	env, idp := oidcEnv(t, true)
+	ctx := context.Background()
+	code, state := startOIDC(t, env, idp, nil)
+
+	tok, err := env.svc.CompleteOIDCLogin(ctx, code, state, ClientInfo{})
+	if err != nil {
+		t.Fatalf("login pertama: %v", err)
+	}
+	if tok.Token == "" || tok.RefreshToken == "" {
+		t.Fatalf("token kosong: %+v", tok)
+	}
+	if _, err := env.svc.CompleteOIDCLogin(ctx, code, state, ClientInfo{}); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Fatalf("state dipakai ulang: err = %v, ingin ErrInvalidOIDCState", err)
+	}
+}
+
+func TestCompleteOIDCLoginRejectsExpiredState(t *testing.T) {
+	env, idp := oidcEnv(t, true)
+	code, state := startOIDC(t, env, idp, nil)
+	env.oidcStates.byHash[hashToken(state)].ExpiresAt = time.Now().Add(-time.Second)
+
+	if _, err := env.svc.CompleteOIDCLogin(context.Background(), code, state, ClientInfo{}); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Fatalf("err = %v, ingin ErrInvalidOIDCState", err)
+	}
+	if len(env.users.byID) != 0 {
+		t.Fatal("user dibuat meski state kedaluwarsa")
+	}
+}
+
+func TestCompleteOIDCLoginAutoProvision(t *testing.T) {
+	env, idp := oidcEnv(t, true)
+	code, state := startOIDC(t, env, idp, nil)
+
+	if _, err := env.svc.CompleteOIDCLogin(context.Background(), code, state, ClientInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	u, _ := env.users.GetByEmail(context.Background(), "sso@example.com")
+	if u == nil {
+		t.Fatal("user tidak dibuat")
+	}
+	if u.Name != "SSO User" || u.PasswordHash != unusablePasswordHash || u.EmailVerifiedAt == nil {
+		t.Fatalf("user hasil provisioning tidak sesuai: %+v", u)
+	}
+}
+
+func TestCompleteOIDCLoginWithoutAutoProvision(t *testing.T) {
+	env, idp := oidcEnv(t, false)
+	code, state := startOIDC(t, env, idp, nil)
+
+	if _, err := env.svc.CompleteOIDCLogin(context.Background(), code, state, ClientInfo{}); !errors.Is(err, ErrOIDCNoAccount) {
+		t.Fatalf("err = %v, ingin ErrOIDCNoAccount", err)
+	}
+	if len(env.users.byID) != 0 {
+		t.Fatal("user dibuat meski auto-provision mati")
+	}
+
+	// user yang sudah terdaftar tetap bisa masuk lewat SSO
+	addUser(t, env.svc, env.users, "u1", "sso@example.com", "Password123!")
+	code, state = startOIDC(t, env, idp, nil)
+	if _, err := env.svc.CompleteOIDCLogin(context.Background(), code, state, ClientInfo{}); err != nil {
+		t.Fatalf("user terdaftar: %v", err)
+	}
+	if env.users.byID["u1"].EmailVerifiedAt == nil {
+		t.Fatal("email user terdaftar tidak ditandai terverifikasi")
+	}
+}
+
+func TestCompleteOIDCLoginRequiresVerifiedEmailForExistingUser(t *testing.T) {
+	env, idp := oidcEnv(t, true)
+	addUser(t, env.svc, env.users, "u1", "sso@example.com", "Password123!")
+
+	for name, edit := range map[string]func(jwt.MapClaims){
+		"email_verified false": func(c jwt.MapClaims) { c["email_verified"] = false },
+		"tanpa email_verified": func(c jwt.MapClaims) { delete(c, "email_verified") },
+	} {
+		code, state := startOIDC(t, env, idp, edit)
+		if _, err := env.svc.CompleteOIDCLogin(context.Background(), code, state, ClientInfo{}); !errors.Is(err, ErrOIDCEmailUnverified) {
+			t.Errorf("%s: err = %v, ingin ErrOIDCEmailUnverified", name, err)
+		}
+	}
+	if len(env.sessions.byID) != 0 {
+		t.Fatal("session dibuat untuk email yang belum diverifikasi")
+	}
+}
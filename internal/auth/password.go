@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"backend-work-mate/internal/storage/postgres"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	HashBcrypt   = "bcrypt"
+	HashArgon2id = "argon2id"
+)
+
+// passwordHasher membuat hash dengan algoritma yang dikonfigurasi dan tetap bisa
+// memverifikasi hash lama (bcrypt maupun argon2id dengan parameter berbeda).
+type passwordHasher struct {
+	algorithm  string
+	bcryptCost int
+	argon      argon2Params
+}
+
+type argon2Params struct {
+	memory  uint32 // KiB
+	time    uint32
+	threads uint8
+}
+
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+func (h passwordHasher) hash(password string) (string, error) {
+	if h.algorithm == HashArgon2id {
+		salt := make([]byte, argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, h.argon.time, h.argon.memory, h.argon.threads, argon2KeyLen)
+		// format PHC: $argon2id$v=19$m=65536,t=3,p=2$salt$hash
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+			h.argon.memory, h.argon.time, h.argon.threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// verify mengecek password terhadap hash. needsRehash bernilai true bila password
+// cocok tetapi hash dibuat dengan algoritma atau parameter yang sudah tidak dipakai.
+func (h passwordHasher) verify(encoded, password string) (ok, needsRehash bool) {
+	if strings.HasPrefix(encoded, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, false
+		}
+		got := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(got, key) != 1 {
+			return false, false
+		}
+		return true, h.algorithm != HashArgon2id || params != h.argon
+	}
+	if bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)) != nil {
+		return false, false
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return true, h.algorithm != HashBcrypt || err != nil || cost != h.bcryptCost
+}
+
+func decodeArgon2id(encoded string) (argon2Params, []byte, []byte, error) {
+	var p argon2Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errors.New("invalid argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errors.New("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return p, nil, nil, err
+	}
+	// argon2.IDKey panic bila t atau p nol
+	if p.time < 1 || p.threads < 1 {
+		return p, nil, nil, errors.New("invalid argon2id parameters")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, errors.New("invalid argon2id hash")
+	}
+	return p, salt, key, nil
+}
+
+// rehashPassword menyimpan ulang hash dengan algoritma/parameter terbaru setelah
+// login berhasil. Kegagalan hanya dicatat; login tetap berjalan.
+func (s *Service) rehashPassword(ctx context.Context, user *postgres.User, password string) {
+	hash, err := s.hasher.hash(password)
+	if err == nil {
+		err = s.users.UpdatePassword(ctx, user.ID, hash)
+	}
+	if err != nil {
+		log.Printf("rehash password for user %s: %v", user.ID, err)
+		return
+	}
+	user.PasswordHash = hash
+}
+
+// dummyPasswordHash mengembalikan hash password acak dengan algoritma dan
+// parameter aktif, dibuat sekali saat pertama dibutuhkan.
+func (s *Service) dummyPasswordHash() string {
+	s.dummyHashOnce.Do(func() {
+		hash, err := s.hasher.hash("workmate-dummy-password")
+		if err != nil {
+			log.Printf("create dummy password hash: %v", err)
+			return
+		}
+		s.dummyHash = hash
+	})
+	return s.dummyHash
+}
+
+// PasswordPolicy mengembalikan kebijakan password aktif (untuk ditampilkan di frontend).
+func (s *Service) PasswordPolicy() PasswordPolicy {
+	return s.policy
+}
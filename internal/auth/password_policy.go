@@ -0,0 +1,102 @@
+package auth
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var commonPasswords = parseCommonPasswords(commonPasswordsFile)
+
+func parseCommonPasswords(data string) map[string]struct{} {
+	out := map[string]struct{}{}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out[strings.ToLower(line)] = struct{}{}
+	}
+	return out
+}
+
+// maxPasswordLength membatasi biaya hashing; bcrypt sendiri hanya memakai 72 byte pertama.
+const maxPasswordLength = 128
+
+// PasswordPolicy adalah aturan password baru (registrasi, ganti/reset password, undangan).
+type PasswordPolicy struct {
+	MinLength     int  `json:"min_length"`
+	RequireUpper  bool `json:"require_upper"`
+	RequireLower  bool `json:"require_lower"`
+	RequireDigit  bool `json:"require_digit"`
+	RequireSymbol bool `json:"require_symbol"`
+	RejectCommon  bool `json:"reject_common"`
+}
+
+// PasswordPolicyError berisi semua aturan yang dilanggar agar bisa ditampilkan sekaligus.
+type PasswordPolicyError struct {
+	Problems []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password tidak memenuhi kebijakan: " + strings.Join(e.Problems, ", ")
+}
+
+// Validate mengembalikan *PasswordPolicyError bila password melanggar aturan.
+// email dipakai untuk menolak password yang sama dengan alamat email atau bagian depannya.
+func (p PasswordPolicy) Validate(password, email string) error {
+	var problems []string
+	n := utf8.RuneCountInString(password)
+	if n < p.MinLength {
+		problems = append(problems, fmt.Sprintf("minimal %d karakter", p.MinLength))
+	}
+	if n > maxPasswordLength {
+		problems = append(problems, fmt.Sprintf("maksimal %d karakter", maxPasswordLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		problems = append(problems, "harus mengandung huruf besar")
+	}
+	if p.RequireLower && !lower {
+		problems = append(problems, "harus mengandung huruf kecil")
+	}
+	if p.RequireDigit && !digit {
+		problems = append(problems, "harus mengandung angka")
+	}
+	if p.RequireSymbol && !symbol {
+		problems = append(problems, "harus mengandung simbol")
+	}
+
+	if p.RejectCommon {
+		lowered := strings.ToLower(password)
+		local, _, _ := strings.Cut(strings.ToLower(email), "@")
+		if _, ok := commonPasswords[lowered]; ok {
+			problems = append(problems, "terlalu umum")
+		} else if email != "" && (lowered == strings.ToLower(email) || lowered == local) {
+			problems = append(problems, "tidak boleh sama dengan email")
+		}
+	}
+
+	if len(problems) > 0 {
+		return &PasswordPolicyError{Problems: problems}
+	}
+	return nil
+}
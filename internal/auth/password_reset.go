@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"backend-work-mate/internal/mail"
+	"backend-work-mate/internal/storage/postgres"
+)
+
+var ErrInvalidResetToken = errors.New("token reset password tidak valid atau sudah kedaluwarsa")
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ForgotPassword mengirim link reset password bila email terdaftar dan aktif.
+// Hasilnya sengaja sama untuk email yang tidak terdaftar agar tidak bisa dipakai
+// untuk menebak akun.
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return nil
+	}
+
+	token, err := s.createUserToken(ctx, user.ID, postgres.TokenPurposePasswordReset, s.resetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.appBaseURL + "/reset-password?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset password Workmate",
+		Body: fmt.Sprintf("Halo %s,\n\nKami menerima permintaan reset password untuk akun Workmate kamu.\n"+
+			"Buka link berikut untuk membuat password baru (berlaku %s):\n\n%s\n\n"+
+			"Abaikan email ini bila kamu tidak meminta reset password.\n", user.Name, s.resetTTL, link),
+	})
+}
+
+// createUserToken menerbitkan token sekali pakai baru untuk purpose tertentu dan
+// membatalkan token lama yang belum terpakai.
+func (s *Service) createUserToken(ctx context.Context, userID, purpose string, ttl time.Duration) (string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.userTokens.DeleteForUser(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+	if err := s.userTokens.Create(ctx, &postgres.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ResetPassword mengganti password memakai token dari email, lalu mencabut semua sesi user.
+func (s *Service) ResetPassword(ctx context.Context, in ResetPasswordInput) error {
+	// policy dicek sebelum token dipakai agar user bisa mencoba lagi dengan link yang sama
+	pending, err := s.userTokens.Get(ctx, postgres.TokenPurposePasswordReset, hashToken(in.Token))
+	if err != nil {
+		return err
+	}
+	if pending == nil {
+		return ErrInvalidResetToken
+	}
+	user, err := s.users.GetByID(ctx, pending.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidResetToken
+	}
+	if err := s.policy.Validate(in.NewPassword, user.Email); err != nil {
+		return err
+	}
+	t, err := s.userTokens.Consume(ctx, postgres.TokenPurposePasswordReset, hashToken(in.Token))
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return ErrInvalidResetToken
+	}
+	hash, err := s.hasher.hash(in.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdatePassword(ctx, t.UserID, hash); err != nil {
+		return err
+	}
+	return s.LogoutAll(ctx, t.UserID)
+}
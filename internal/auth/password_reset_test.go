@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend-work-mate/internal/storage/postgres"
+)
+
+func TestResetPasswordChecksPolicyAgainstUserEmail(t *testing.T) {
+	env := newTestEnv(t, nil)
+	ctx := context.Background()
+	addUser(t, env.svc, env.users, "u1", "Budi.Santoso@example.com", "Secret123!")
+	token, err := env.svc.createUserToken(ctx, "u1", postgres.TokenPurposePasswordReset, env.svc.resetTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var policyErr *PasswordPolicyError
+	err = env.svc.ResetPassword(ctx, ResetPasswordInput{Token: token, NewPassword: "budi.santoso"})
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("password sama dengan email: err = %v, ingin PasswordPolicyError", err)
+	}
+	if ok, _ := env.svc.hasher.verify(env.users.byID["u1"].PasswordHash, "Secret123!"); !ok {
+		t.Fatal("password berubah meski ditolak kebijakan")
+	}
+
+	// token yang sama masih bisa dipakai setelah password ditolak
+	if err := env.svc.ResetPassword(ctx, ResetPasswordInput{Token: token, NewPassword: "Kopi-Tubruk-77"}); err != nil {
+		t.Fatalf("reset dengan password valid: %v", err)
+	}
+	u := env.users.byID["u1"]
+	if ok, _ := env.svc.hasher.verify(u.PasswordHash, "Kopi-Tubruk-77"); !ok {
+		t.Fatal("password baru tidak tersimpan")
+	}
+	if u.TokenVersion != 1 {
+		t.Fatalf("token version = %d, ingin 1 (semua sesi dicabut)", u.TokenVersion)
+	}
+	if err := env.svc.ResetPassword(ctx, ResetPasswordInput{Token: token, NewPassword: "Kopi-Tubruk-78"}); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("token dipakai ulang: err = %v, ingin ErrInvalidResetToken", err)
+	}
+}
+
+func TestResetPasswordInvalidToken(t *testing.T) {
+	env := newTestEnv(t, nil)
+	err := env.svc.ResetPassword(context.Background(), ResetPasswordInput{Token: "tidak-ada", NewPassword: "Kopi-Tubruk-77"})
+	if !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("err = %v, ingin ErrInvalidResetToken", err)
+	}
+}
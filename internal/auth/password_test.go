@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"backend-work-mate/internal/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon adalah parameter argon2id kecil agar test cepat.
+var testArgon = argon2Params{memory: 64, time: 1, threads: 1}
+
+func TestArgon2idHashRoundTrip(t *testing.T) {
+	h := passwordHasher{algorithm: HashArgon2id, argon: testArgon}
+	encoded, err := h.hash("Secret123!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("format hash = %q", encoded)
+	}
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params != testArgon || len(salt) != argon2SaltLen || len(key) != argon2KeyLen {
+		t.Fatalf("decode = %+v, salt %d byte, key %d byte", params, len(salt), len(key))
+	}
+	if again, _ := h.hash("Secret123!"); again == encoded {
+		t.Fatal("salt tidak acak")
+	}
+
+	if ok, rehash := h.verify(encoded, "Secret123!"); !ok || rehash {
+		t.Fatalf("verify = %v, %v; ingin true, false", ok, rehash)
+	}
+	if ok, _ := h.verify(encoded, "Secret123?"); ok {
+		t.Fatal("password salah diterima")
+	}
+	stronger := passwordHasher{algorithm: HashArgon2id, argon: argon2Params{memory: 128, time: 1, threads: 1}}
+	if ok, rehash := stronger.verify(encoded, "Secret123!"); !ok || !rehash {
+		t.Fatalf("parameter berubah: verify = %v, %v; ingin true, true", ok, rehash)
+	}
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	bcrypt4 := passwordHasher{algorithm: HashBcrypt, bcryptCost: 4}
+	bcrypt5 := passwordHasher{algorithm: HashBcrypt, bcryptCost: 5}
+	argon := passwordHasher{algorithm: HashArgon2id, argon: testArgon}
+	bcryptHash, err := bcrypt4.hash("Secret123!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	argonHash, err := argon.hash("Secret123!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		h       passwordHasher
+		encoded string
+		rehash  bool
+	}{
+		{"bcrypt cost sama", bcrypt4, bcryptHash, false},
+		{"bcrypt cost berubah", bcrypt5, bcryptHash, true},
+		{"bcrypt ke argon2id", argon, bcryptHash, true},
+		{"argon2id ke bcrypt", bcrypt4, argonHash, true},
+	}
+	for _, tt := range tests {
+		ok, rehash := tt.h.verify(tt.encoded, "Secret123!")
+		if !ok || rehash != tt.rehash {
+			t.Errorf("%s: verify = %v, %v; ingin true, %v", tt.name, ok, rehash, tt.rehash)
+		}
+	}
+}
+
+func TestMalformedPasswordHash(t *testing.T) {
+	argon := []string{
+		"$argon2id$",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=1,p=0$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=1,p=300$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$!!!$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$",
+	}
+	for _, encoded := range argon {
+		if _, _, _, err := decodeArgon2id(encoded); err == nil {
+			t.Errorf("decodeArgon2id(%q) tidak error", encoded)
+		}
+	}
+	h := passwordHasher{algorithm: HashArgon2id, argon: testArgon}
+	for _, encoded := range append(argon, "", "bukan-hash", "$2a$04$pendek", unusablePasswordHash) {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("verify(%q) panic: %v", encoded, r)
+				}
+			}()
+			if ok, rehash := h.verify(encoded, "Secret123!"); ok || rehash {
+				t.Errorf("verify(%q) = %v, %v; ingin false, false", encoded, ok, rehash)
+			}
+		}()
+	}
+}
+
+func TestLoginRehashesBcryptToArgon2id(t *testing.T) {
+	env := newTestEnv(t, func(cfg *config.Config) {
+		cfg.PasswordHashAlgorithm = HashArgon2id
+		cfg.Argon2MemoryKB = int(testArgon.memory)
+		cfg.Argon2Iterations = int(testArgon.time)
+		cfg.Argon2Parallelism = int(testArgon.threads)
+	})
+	ctx := context.Background()
+	old, err := bcrypt.GenerateFromPassword([]byte("Secret123!"), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addUser(t, env.svc, env.users, "u1", "budi@example.com", "x")
+	u := env.users.byID["u1"]
+	u.PasswordHash = string(old)
+
+	if _, err := env.svc.Login(ctx, LoginInput{Email: u.Email, Password: "salah"}, ClientInfo{}); err == nil {
+		t.Fatal("login dengan password salah berhasil")
+	}
+	if u.PasswordHash != string(old) {
+		t.Fatal("hash di-upgrade meski password salah")
+	}
+
+	if _, err := env.svc.Login(ctx, LoginInput{Email: u.Email, Password: "Secret123!"}, ClientInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(u.PasswordHash, "$argon2id$") {
+		t.Fatalf("hash tidak di-upgrade ke argon2id: %q", u.PasswordHash)
+	}
+	upgraded := u.PasswordHash
+	if _, err := env.svc.Login(ctx, LoginInput{Email: u.Email, Password: "Secret123!"}, ClientInfo{}); err != nil {
+		t.Fatalf("login setelah upgrade: %v", err)
+	}
+	if u.PasswordHash != upgraded {
+		t.Fatal("hash argon2id yang sudah terbaru di-hash ulang")
+	}
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true, RejectCommon: true}
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		email    string
+		problems []string
+	}{
+		{"lolos semua aturan", strict, "Kopi-Tubruk-77", "budi@example.com", nil},
+		{"terlalu pendek", PasswordPolicy{MinLength: 8}, "abc", "", []string{"minimal 8 karakter"}},
+		{"panjang dihitung per karakter", PasswordPolicy{MinLength: 4}, "日本語!", "", nil},
+		{"terlalu panjang", PasswordPolicy{}, strings.Repeat("a", maxPasswordLength+1), "", []string{"maksimal 128 karakter"}},
+		{"kelas karakter kurang", strict, "abcdefghijk", "", []string{
+			"harus mengandung huruf besar", "harus mengandung angka", "harus mengandung simbol",
+		}},
+		{"password umum", PasswordPolicy{RejectCommon: true}, "Password", "", []string{"terlalu umum"}},
+		{"sama dengan email", PasswordPolicy{RejectCommon: true}, "Budi@Example.com", "budi@example.com", []string{"tidak boleh sama dengan email"}},
+		{"sama dengan bagian depan email", PasswordPolicy{RejectCommon: true}, "budi.santoso", "Budi.Santoso@example.com", []string{"tidak boleh sama dengan email"}},
+		{"email tidak dicek bila RejectCommon mati", PasswordPolicy{}, "budi.santoso", "budi.santoso@example.com", nil},
+	}
+	for _, tt := range tests {
+		err := tt.policy.Validate(tt.password, tt.email)
+		if tt.problems == nil {
+			if err != nil {
+				t.Errorf("%s: err = %v, ingin nil", tt.name, err)
+			}
+			continue
+		}
+		var perr *PasswordPolicyError
+		if !errors.As(err, &perr) {
+			t.Errorf("%s: err = %v, ingin PasswordPolicyError", tt.name, err)
+			continue
+		}
+		if strings.Join(perr.Problems, "|") != strings.Join(tt.problems, "|") {
+			t.Errorf("%s: problems = %q, ingin %q", tt.name, perr.Problems, tt.problems)
+		}
+	}
+}
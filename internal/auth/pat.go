@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend-work-mate/internal/storage/postgres"
+)
+
+// Scope membatasi apa yang boleh dilakukan personal access token.
+// Sesi login biasa (JWT) tidak dibatasi scope.
+const (
+	ScopeTasksRead   = "tasks:read"
+	ScopeTasksWrite  = "tasks:write"
+	ScopeProfileRead = "profile:read"
+)
+
+var validScopes = map[string]bool{
+	ScopeTasksRead:   true,
+	ScopeTasksWrite:  true,
+	ScopeProfileRead: true,
+}
+
+// patPrefix membuat PAT mudah dikenali (mis. oleh secret scanner) dan
+// membedakannya dari JWT di auth middleware.
+const patPrefix = "wmpat_"
+
+var ErrInvalidPAT = errors.New("personal access token tidak valid")
+
+type CreatePATInput struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	// ExpiresInDays 0 atau kosong berarti tidak kedaluwarsa.
+	ExpiresInDays int `json:"expires_in_days" binding:"min=0,max=3650"`
+}
+
+// CreatedPAT berisi token mentah yang hanya ditampilkan sekali saat dibuat.
+type CreatedPAT struct {
+	Token string                        `json:"token"`
+	Info  *postgres.PersonalAccessToken `json:"info"`
+}
+
+// IsPersonalAccessToken melaporkan apakah request diautentikasi dengan PAT.
+func (c *Claims) IsPersonalAccessToken() bool {
+	return c.PersonalAccessTokenID != ""
+}
+
+// HasScope melaporkan apakah claims mengizinkan scope; sesi JWT selalu diizinkan.
+func (c *Claims) HasScope(scope string) bool {
+	if !c.IsPersonalAccessToken() {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) CreatePersonalAccessToken(ctx context.Context, userID string, in CreatePATInput) (*CreatedPAT, error) {
+	scopes := make([]string, 0, len(in.Scopes))
+	seen := map[string]bool{}
+	for _, scope := range in.Scopes {
+		if !validScopes[scope] {
+			return nil, fmt.Errorf("scope tidak dikenal: %q", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	raw := patPrefix + secret
+	t := &postgres.PersonalAccessToken{
+		UserID:    userID,
+		Name:      in.Name,
+		Prefix:    raw[:len(patPrefix)+6],
+		TokenHash: hashToken(raw),
+		Scopes:    scopes,
+	}
+	if in.ExpiresInDays > 0 {
+		exp := time.Now().Add(time.Duration(in.ExpiresInDays) * 24 * time.Hour)
+		t.ExpiresAt = &exp
+	}
+	if err := s.pats.Create(ctx, t); err != nil {
+		return nil, err
+	}
+	return &CreatedPAT{Token: raw, Info: t}, nil
+}
+
+func (s *Service) ListPersonalAccessTokens(ctx context.Context, userID string) ([]postgres.PersonalAccessToken, error) {
+	return s.pats.ListByUser(ctx, userID)
+}
+
+func (s *Service) RevokePersonalAccessToken(ctx context.Context, userID, id string) error {
+	return s.pats.Revoke(ctx, userID, id)
+}
+
+// authenticatePAT memvalidasi PAT dan membangun Claims sintetis untuk middleware.
+func (s *Service) authenticatePAT(ctx context.Context, raw string) (*Claims, error) {
+	t, err := s.pats.GetByHash(ctx, hashToken(raw))
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || t.RevokedAt != nil {
+		return nil, ErrInvalidPAT
+	}
+	if t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt) {
+		return nil, ErrInvalidPAT
+	}
+	user, err := s.users.GetByID(ctx, t.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidPAT
+	}
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	if err := s.pats.Touch(ctx, t.ID); err != nil {
+		return nil, err
+	}
+	claims := &Claims{
+		Email:                 user.Email,
+		Role:                  user.Role,
+		Version:               user.TokenVersion,
+		Scopes:                t.Scopes,
+		PersonalAccessTokenID: t.ID,
+	}
+	claims.Subject = user.ID
+	return claims, nil
+}
+
+func isPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, patPrefix)
+}
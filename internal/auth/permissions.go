@@ -0,0 +1,26 @@
+package auth
+
+import "backend-work-mate/internal/storage/postgres"
+
+// Permission adalah hak akses granular yang diturunkan dari role user.
+type Permission string
+
+const (
+	PermTasksReadAll Permission = "tasks:read:all"
+	PermUsersManage  Permission = "users:manage"
+)
+
+var rolePermissions = map[postgres.UserRole][]Permission{
+	postgres.RoleAdmin:    {PermTasksReadAll, PermUsersManage},
+	postgres.RoleEmployee: {},
+}
+
+// HasPermission melaporkan apakah role memiliki permission tertentu.
+func HasPermission(role postgres.UserRole, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// loginForRefresh login sebagai user tanpa MFA dan mengembalikan token awal.
+func loginForRefresh(t *testing.T) (*testEnv, *AuthToken) {
+	t.Helper()
+	env := newTestEnv(t, nil)
+	addUser(t, env.svc, env.users, "u1", "budi@example.com", "Secret123!")
+	tok, err := env.svc.Login(context.Background(), LoginInput{Email: "budi@example.com", Password: "Secret123!"}, ClientInfo{IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.RefreshToken == "" {
+		t.Fatalf("login tidak menerbitkan refresh token: %+v", tok)
+	}
+	return env, tok
+}
+
+// assertFamilyRevoked memastikan session dan semua refresh token-nya dicabut.
+func assertFamilyRevoked(t *testing.T, env *testEnv) {
+	t.Helper()
+	for _, s := range env.sessions.byID {
+		if s.RevokedAt == nil {
+			t.Errorf("session %s belum dicabut", s.ID)
+		}
+	}
+	for _, rt := range env.refreshTokens.byHash {
+		if rt.RevokedAt == nil {
+			t.Errorf("refresh token %s belum dicabut", rt.ID)
+		}
+	}
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	env, tok := loginForRefresh(t)
+	next, err := env.svc.Refresh(context.Background(), tok.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.RefreshToken == "" || next.RefreshToken == tok.RefreshToken {
+		t.Fatalf("refresh token tidak dirotasi: %+v", next)
+	}
+	if _, err := env.svc.Refresh(context.Background(), next.RefreshToken); err != nil {
+		t.Fatalf("refresh token hasil rotasi ditolak: %v", err)
+	}
+}
+
+func TestRefreshReplayRevokesFamily(t *testing.T) {
+	env, tok := loginForRefresh(t)
+	ctx := context.Background()
+	next, err := env.svc.Refresh(ctx, tok.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// token lama dipakai ulang (mis. dicuri): seluruh family ikut dicabut
+	if got, err := env.svc.Refresh(ctx, tok.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) || got != nil {
+		t.Fatalf("replay: token = %v, err = %v, ingin ErrRefreshTokenReused", got, err)
+	}
+	assertFamilyRevoked(t, env)
+	if _, err := env.svc.Refresh(ctx, next.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("token terbaru setelah replay: err = %v, ingin ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRefreshLosingMarkUsedRace(t *testing.T) {
+	env, tok := loginForRefresh(t)
+	ctx := context.Background()
+
+	// request kedua selesai tepat setelah request pertama membaca token, sehingga
+	// request pertama kalah saat MarkUsed
+	var winner *AuthToken
+	var winnerErr error
+	env.refreshTokens.afterGet = func() {
+		env.refreshTokens.afterGet = nil
+		winner, winnerErr = env.svc.Refresh(ctx, tok.RefreshToken)
+	}
+	loser, err := env.svc.Refresh(ctx, tok.RefreshToken)
+	if winnerErr != nil || winner == nil {
+		t.Fatalf("request pemenang gagal: %v", winnerErr)
+	}
+	// handler memetakan semua error Refresh ke 401
+	if !errors.Is(err, ErrRefreshTokenReused) || loser != nil {
+		t.Fatalf("request kalah: token = %v, err = %v, ingin ErrRefreshTokenReused", loser, err)
+	}
+	// token login + satu token milik pemenang; yang kalah tidak menerbitkan pasangan baru
+	if n := len(env.refreshTokens.byHash); n != 2 {
+		t.Fatalf("jumlah refresh token = %d, ingin 2", n)
+	}
+	assertFamilyRevoked(t, env)
+}
+
+func TestRefreshConcurrentOnlyOneSucceeds(t *testing.T) {
+	env, tok := loginForRefresh(t)
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = env.svc.Refresh(context.Background(), tok.RefreshToken)
+		}()
+	}
+	wg.Wait()
+	ok := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			ok++
+		case !errors.Is(err, ErrRefreshTokenReused) && !errors.Is(err, ErrInvalidRefreshToken):
+			// request yang membaca token setelah family dicabut mendapat ErrInvalidRefreshToken
+			t.Errorf("err = %v, ingin ErrRefreshTokenReused atau ErrInvalidRefreshToken", err)
+		}
+	}
+	if ok > 1 {
+		t.Fatalf("%d request berhasil memakai refresh token yang sama", ok)
+	}
+}
@@ -3,31 +3,148 @@ package auth
 import (
 	"context"
 	"errors"
+	"log"
+	"strings"
+	"sync"
 	"time"
 
 	"backend-work-mate/internal/config"
+	"backend-work-mate/internal/mail"
+	"backend-work-mate/internal/oidc"
 	"backend-work-mate/internal/storage/postgres"
 
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/jackc/pgx/v5"
 )
 
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token tidak valid")
+	ErrRefreshTokenReused  = errors.New("refresh token sudah dipakai, sesi dicabut")
+	ErrTokenRevoked        = errors.New("token sudah dicabut")
+	ErrAccountDisabled     = errors.New("akun dinonaktifkan")
+	ErrUserNotFound        = errors.New("user tidak ditemukan")
+	ErrEmailNotVerified    = errors.New("email belum diverifikasi")
+	ErrEmailTaken          = errors.New("email sudah terdaftar")
+	ErrRegistrationClosed  = errors.New("registrasi mandiri dinonaktifkan, minta undangan dari Admin")
+)
+
+// Store mengelompokkan repository yang dibutuhkan Service.
+type Store struct {
+	Users         postgres.UserRepository
+	RefreshTokens postgres.RefreshTokenRepository
+	RevokedTokens postgres.RevokedTokenRepository
+	UserTokens    postgres.UserTokenRepository
+	MFA           postgres.MFARepository
+	LoginAttempts postgres.LoginAttemptRepository
+	PATs          postgres.PersonalAccessTokenRepository
+	OIDCStates    postgres.OIDCStateRepository
+	Sessions      postgres.SessionRepository
+	Invitations   postgres.InvitationRepository
+}
+
 type Service struct {
-	users     postgres.UserRepository
-	jwtSecret []byte
+	users         postgres.UserRepository
+	refreshTokens postgres.RefreshTokenRepository
+	revokedTokens postgres.RevokedTokenRepository
+	userTokens    postgres.UserTokenRepository
+	mfa           postgres.MFARepository
+	loginAttempts postgres.LoginAttemptRepository
+	pats          postgres.PersonalAccessTokenRepository
+	oidcStates    postgres.OIDCStateRepository
+	sessions      postgres.SessionRepository
+	invitations   postgres.InvitationRepository
+	mailer        mail.Mailer
+	keys          *KeySet
+	accessTTL     time.Duration
+	refreshTTL    time.Duration
+	resetTTL      time.Duration
+	verifyTTL     time.Duration
+	requireVerify bool
+	allowRegister bool
+	inviteTTL     time.Duration
+	appBaseURL    string
+	mfaIssuer     string
+	policy        PasswordPolicy
+	hasher        passwordHasher
+	lockout       lockoutPolicy
+	// dummyHash diverifikasi saat login dengan email yang tidak terdaftar
+	dummyHash     string
+	dummyHashOnce sync.Once
+	// oidc nil bila SSO tidak dikonfigurasi
+	oidc              *oidc.Provider
+	oidcAutoProvision bool
+	// oidcAllowUnverified mengizinkan provisioning user baru dari ID token tanpa
+	// claim email_verified
+	oidcAllowUnverified bool
 }
 
-func NewService(repo postgres.UserRepository, cfg *config.Config) *Service {
+func NewService(store Store, mailer mail.Mailer, keys *KeySet, cfg *config.Config) *Service {
+	var provider *oidc.Provider
+	if cfg.OIDCIssuer != "" {
+		provider = oidc.NewProvider(oidc.Config{
+			Issuer:       cfg.OIDCIssuer,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+			HTTPClient:   cfg.OIDCHTTPClient,
+		})
+	}
 	return &Service{
-		users:     repo,
-		jwtSecret: []byte(cfg.JWTSecret),
+		users:         store.Users,
+		refreshTokens: store.RefreshTokens,
+		revokedTokens: store.RevokedTokens,
+		userTokens:    store.UserTokens,
+		mfa:           store.MFA,
+		loginAttempts: store.LoginAttempts,
+		pats:          store.PATs,
+		oidcStates:    store.OIDCStates,
+		sessions:      store.Sessions,
+		invitations:   store.Invitations,
+		mailer:        mailer,
+		keys:          keys,
+		accessTTL:     cfg.AccessTokenTTL,
+		refreshTTL:    cfg.RefreshTokenTTL,
+		resetTTL:      cfg.PasswordResetTTL,
+		verifyTTL:     cfg.EmailVerificationTTL,
+		requireVerify: cfg.RequireEmailVerification,
+		allowRegister: cfg.AllowSelfRegistration,
+		inviteTTL:     cfg.InvitationTTL,
+		appBaseURL:    strings.TrimRight(cfg.AppBaseURL, "/"),
+		mfaIssuer:     cfg.MFAIssuer,
+		policy: PasswordPolicy{
+			MinLength:     cfg.PasswordMinLength,
+			RequireUpper:  cfg.PasswordRequireUpper,
+			RequireLower:  cfg.PasswordRequireLower,
+			RequireDigit:  cfg.PasswordRequireDigit,
+			RequireSymbol: cfg.PasswordRequireSymbol,
+			RejectCommon:  cfg.PasswordRejectCommon,
+		},
+		hasher: passwordHasher{
+			algorithm:  cfg.PasswordHashAlgorithm,
+			bcryptCost: cfg.BcryptCost,
+			argon: argon2Params{
+				memory:  uint32(cfg.Argon2MemoryKB),
+				time:    uint32(cfg.Argon2Iterations),
+				threads: uint8(cfg.Argon2Parallelism),
+			},
+		},
+		lockout: lockoutPolicy{
+			maxPerEmail: cfg.LoginMaxFailuresPerEmail,
+			maxPerIP:    cfg.LoginMaxFailuresPerIP,
+			base:        cfg.LoginLockoutBase,
+			max:         cfg.LoginLockoutMax,
+			window:      cfg.LoginFailureWindow,
+		},
+		oidc:                provider,
+		oidcAutoProvision:   cfg.OIDCAutoProvision,
+		oidcAllowUnverified: cfg.OIDCAllowUnverifiedEmail,
 	}
 }
 
 type RegisterInput struct {
 	Name       string  `json:"name" binding:"required"`
 	Email      string  `json:"email" binding:"required,email"`
-	Password   string  `json:"password" binding:"required,min=6"`
+	Password   string  `json:"password" binding:"required"`
 	Department *string `json:"department"`
 }
 
@@ -36,80 +153,319 @@ type LoginInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// AuthToken adalah hasil login. Bila MFARequired bernilai true, hanya MFAToken
+// yang terisi dan harus ditukar lewat VerifyMFA.
 type AuthToken struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
 }
 
 func (s *Service) Register(ctx context.Context, in RegisterInput) (*postgres.User, error) {
+	if !s.allowRegister {
+		return nil, ErrRegistrationClosed
+	}
 	existing, err := s.users.GetByEmail(ctx, in.Email)
 	if err != nil {
 		return nil, err
 	}
 	if existing != nil {
-		return nil, errors.New("email sudah terdaftar")
+		return nil, ErrEmailTaken
+	}
+	if err := s.policy.Validate(in.Password, in.Email); err != nil {
+		return nil, err
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+	hash, err := s.hasher.hash(in.Password)
 	if err != nil {
 		return nil, err
 	}
 	user := &postgres.User{
 		Name:         in.Name,
 		Email:        in.Email,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 		Role:         postgres.RoleEmployee,
 		Department:   in.Department,
 	}
 	if err := s.users.Create(ctx, user); err != nil {
 		return nil, err
 	}
+	// kegagalan kirim email tidak menggagalkan registrasi; user bisa minta kirim ulang
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("send verification email to %s: %v", user.Email, err)
+	}
 	return user, nil
 }
 
-func (s *Service) Login(ctx context.Context, in LoginInput) (*AuthToken, error) {
+func (s *Service) Login(ctx context.Context, in LoginInput, client ClientInfo) (*AuthToken, error) {
+	if err := s.checkLockout(ctx, in.Email, client.IP); err != nil {
+		return nil, err
+	}
 	user, err := s.users.GetByEmail(ctx, in.Email)
 	if err != nil {
 		return nil, err
 	}
-	if user == nil {
-		return nil, errors.New("email atau password salah")
+	// email tidak terdaftar tetap diverifikasi terhadap hash dummy agar waktu
+	// respons tidak membocorkan akun mana yang ada
+	hash := s.dummyPasswordHash()
+	if user != nil {
+		hash = user.PasswordHash
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(in.Password)); err != nil {
+	ok, needsRehash := s.hasher.verify(hash, in.Password)
+	if !ok || user == nil {
+		if err := s.recordLoginFailure(ctx, in.Email, client.IP); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("email atau password salah")
 	}
+	if needsRehash {
+		s.rehashPassword(ctx, user, in.Password)
+	}
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	if s.requireVerify && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+	if err := s.resetLoginFailures(ctx, in.Email); err != nil {
+		return nil, err
+	}
+	return s.completeLogin(ctx, user, client)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub":   user.ID,
-		"email": user.Email,
-		"role":  user.Role,
-		"exp":   time.Now().Add(24 * time.Hour).Unix(),
-		"iat":   time.Now().Unix(),
-	})
-	signed, err := token.SignedString(s.jwtSecret)
+// completeLogin membuka session untuk user yang sudah terautentikasi, atau menerbitkan
+// token tantangan MFA bila user mengaktifkan TOTP.
+func (s *Service) completeLogin(ctx context.Context, user *postgres.User, client ClientInfo) (*AuthToken, error) {
+	mfa, err := s.mfa.Get(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
-	return &AuthToken{Token: signed}, nil
+	if mfa != nil && mfa.EnabledAt != nil {
+		challenge, err := s.signMFAToken(user, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return &AuthToken{MFARequired: true, MFAToken: challenge}, nil
+	}
+	return s.startSession(ctx, user, client, false)
 }
 
-// ParseAndValidateJWT memverifikasi token HS256 dan mengembalikan userID dari claim "sub".
-func ParseAndValidateJWT(tokenString string, secret []byte) (string, error) {
-	parsed, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
+// Refresh menukar refresh token dengan pasangan token baru (rotasi). Refresh token
+// yang sudah pernah dipakai dianggap dicuri: seluruh family-nya langsung dicabut.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*AuthToken, error) {
+	rt, err := s.refreshTokens.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if rt == nil || rt.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if rt.UsedAt != nil {
+		if err := s.revokeSession(ctx, rt.UserID, rt.FamilyID); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if time.Now().After(rt.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	ok, err := s.refreshTokens.MarkUsed(ctx, rt.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.revokeSession(ctx, rt.UserID, rt.FamilyID); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
 		}
-		return secret, nil
-	})
+		return nil, ErrRefreshTokenReused
+	}
+	session, err := s.sessions.GetByID(ctx, rt.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.users.GetByID(ctx, rt.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	tokens, err := s.issueTokens(ctx, user, session.ID, rt.MFA)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if !parsed.Valid {
-		return "", errors.New("invalid token")
+	if err := s.sessions.Extend(ctx, session.ID, time.Now().Add(s.refreshTTL)); err != nil {
+		return nil, err
 	}
-	if claims, ok := parsed.Claims.(jwt.MapClaims); ok {
-		if sub, ok := claims["sub"].(string); ok {
-			return sub, nil
+	return tokens, nil
+}
+
+// Authenticate memvalidasi access token lalu memastikan token belum dicabut,
+// baik lewat denylist jti, session yang sudah diakhiri, maupun karena token_version
+// user sudah dinaikkan.
+// Personal access token (prefix "wmpat_") juga diterima.
+func (s *Service) Authenticate(ctx context.Context, tokenString string) (*Claims, error) {
+	if isPersonalAccessToken(tokenString) {
+		return s.authenticatePAT(ctx, tokenString)
+	}
+	claims, err := ParseAndValidateJWT(tokenString, s.keys)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := s.revokedTokens.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+	// token lama tanpa sid tetap diterima sampai kedaluwarsa
+	if claims.SessionID != "" {
+		session, err := s.sessions.GetByID(ctx, claims.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if session == nil || session.RevokedAt != nil || session.UserID != claims.Subject {
+			return nil, ErrTokenRevoked
+		}
+		if err := s.sessions.Touch(ctx, session.ID); err != nil {
+			return nil, err
+		}
+	}
+	user, err := s.users.GetByID(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.TokenVersion != claims.Version {
+		return nil, ErrTokenRevoked
+	}
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	// role selalu diambil dari database agar perubahan role langsung berlaku
+	claims.Role = user.Role
+	return claims, nil
+}
+
+// Logout mencabut access token yang sedang dipakai beserta session-nya dan, bila
+// diberikan, family refresh token milik user yang sama.
+func (s *Service) Logout(ctx context.Context, claims *Claims, refreshToken string) error {
+	if err := s.revokedTokens.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+	if claims.SessionID != "" {
+		if err := s.revokeSession(ctx, claims.Subject, claims.SessionID); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
 		}
 	}
-	return "", errors.New("invalid claims")
+	if refreshToken == "" {
+		return nil
+	}
+	rt, err := s.refreshTokens.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if rt == nil || rt.UserID != claims.Subject {
+		return nil
+	}
+	if err := s.revokeSession(ctx, rt.UserID, rt.FamilyID); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+// LogoutAll mencabut semua session, access token dan refresh token milik user.
+func (s *Service) LogoutAll(ctx context.Context, userID string) error {
+	if err := s.users.IncrementTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.sessions.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.refreshTokens.RevokeAllForUser(ctx, userID)
+}
+
+// ChangePassword mengganti password setelah memverifikasi password lama. Semua sesi
+// lain dicabut; session baru dibuka agar pemanggil tetap login.
+func (s *Service) ChangePassword(ctx context.Context, claims *Claims, in ChangePasswordInput, client ClientInfo) (*AuthToken, error) {
+	user, err := s.users.GetByID(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user tidak ditemukan")
+	}
+	if ok, _ := s.hasher.verify(user.PasswordHash, in.CurrentPassword); !ok {
+		return nil, errors.New("password saat ini salah")
+	}
+	if err := s.policy.Validate(in.NewPassword, user.Email); err != nil {
+		return nil, err
+	}
+	hash, err := s.hasher.hash(in.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.UpdatePassword(ctx, user.ID, hash); err != nil {
+		return nil, err
+	}
+	if err := s.LogoutAll(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	user.TokenVersion++
+	return s.startSession(ctx, user, client, claims.MFA)
+}
+
+// issueTokens membuat access token berumur pendek dan refresh token baru untuk
+// session yang sudah ada. Family refresh token sama dengan ID session.
+func (s *Service) issueTokens(ctx context.Context, user *postgres.User, sessionID string, mfa bool) (*AuthToken, error) {
+	now := time.Now()
+	signed, err := s.signAccessToken(user, now, sessionID, mfa)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	rt := &postgres.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  sessionID,
+		TokenHash: hashToken(refresh),
+		MFA:       mfa,
+		ExpiresAt: now.Add(s.refreshTTL),
+	}
+	if err := s.refreshTokens.Create(ctx, rt); err != nil {
+		return nil, err
+	}
+
+	return &AuthToken{
+		Token:        signed,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.accessTTL.Seconds()),
+	}, nil
 }
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"backend-work-mate/internal/storage/postgres"
+)
+
+// SessionInfo adalah session aktif yang ditampilkan ke user; Current menandai
+// session milik token yang sedang dipakai.
+type SessionInfo struct {
+	postgres.Session
+	Current bool `json:"current"`
+}
+
+// startSession mencatat session login baru lalu menerbitkan token untuknya.
+func (s *Service) startSession(ctx context.Context, user *postgres.User, client ClientInfo, mfa bool) (*AuthToken, error) {
+	session := &postgres.Session{
+		UserID:    user.ID,
+		UserAgent: truncate(client.UserAgent, 512),
+		IP:        client.IP,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, user, session.ID, mfa)
+}
+
+// ListSessions mengembalikan session aktif milik user, terbaru lebih dulu.
+func (s *Service) ListSessions(ctx context.Context, userID, currentSessionID string) ([]SessionInfo, error) {
+	sessions, err := s.sessions.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		out = append(out, SessionInfo{Session: session, Current: session.ID == currentSessionID})
+	}
+	return out, nil
+}
+
+// RevokeSession mengakhiri satu session milik user. Access token dari session
+// tersebut langsung ditolak dan refresh token-nya tidak bisa dipakai lagi.
+// Mengembalikan pgx.ErrNoRows bila session tidak ditemukan atau sudah berakhir.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	return s.revokeSession(ctx, userID, sessionID)
+}
+
+func (s *Service) revokeSession(ctx context.Context, userID, sessionID string) error {
+	if err := s.refreshTokens.RevokeFamily(ctx, sessionID); err != nil {
+		return err
+	}
+	return s.sessions.Revoke(ctx, userID, sessionID)
+}
+
+// truncate memotong v menjadi paling banyak n byte tanpa memutus karakter UTF-8.
+func truncate(v string, n int) string {
+	if len(v) <= n {
+		return v
+	}
+	for n > 0 && !utf8.RuneStart(v[n]) {
+		n--
+	}
+	return v[:n]
+}
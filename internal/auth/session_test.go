@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		v    string
+		n    int
+		want string
+	}{
+		{"Mozilla", 10, "Mozilla"},
+		{"Mozilla", 7, "Mozilla"},
+		{"Mozilla", 3, "Moz"},
+		{"aé", 2, "a"},  // é dua byte, tidak dipotong di tengah
+		{"a日本", 3, "a"}, // 日 tiga byte
+		{"a日本", 4, "a日"},
+		{"日本", 0, ""},
+	}
+	for _, tt := range tests {
+		got := truncate(tt.v, tt.n)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.v, tt.n, got, tt.want)
+		}
+	}
+}
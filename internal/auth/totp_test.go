@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend-work-mate/internal/storage/postgres"
+)
+
+// Secret "12345678901234567890" dari test vector RFC 6238 dalam base32.
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// RFC 6238 memakai 8 digit; 6 digit terakhirnya adalah kode 6 digit.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := totpCode(testTOTPSecret, tt.unix/totpPeriod)
+		if err != nil {
+			t.Fatalf("totpCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("totpCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	step := now.Unix() / totpPeriod
+	tests := []struct {
+		name   string
+		offset int64
+		ok     bool
+	}{
+		{"langkah sekarang", 0, true},
+		{"satu langkah sebelumnya", -1, true},
+		{"satu langkah sesudahnya", 1, true},
+		{"dua langkah sebelumnya", -2, false},
+		{"dua langkah sesudahnya", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := totpCode(testTOTPSecret, step+tt.offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := validateTOTP(testTOTPSecret, code, now)
+			if ok != tt.ok {
+				t.Fatalf("validateTOTP ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && got != step+tt.offset {
+				t.Errorf("validateTOTP step = %d, want %d", got, step+tt.offset)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPRejectsMalformedCode(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := validateTOTP(testTOTPSecret, code, now); ok {
+			t.Errorf("validateTOTP(%q) diterima", code)
+		}
+	}
+}
+
+func TestVerifyTOTPRejectsReplay(t *testing.T) {
+	svc, _, mfa, _ := testService(t)
+	m := &postgres.UserMFA{UserID: "u1", Secret: testTOTPSecret}
+	mfa.byUser["u1"] = m
+
+	code, err := totpCode(testTOTPSecret, time.Now().Unix()/totpPeriod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := svc.verifyTOTP(ctx, m, code); err != nil {
+		t.Fatalf("pemakaian pertama: %v", err)
+	}
+	if err := svc.verifyTOTP(ctx, m, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("pemakaian ulang: err = %v, want ErrInvalidMFACode", err)
+	}
+	// kode dari langkah sebelum langkah yang sudah dipakai juga ditolak
+	prev, err := totpCode(testTOTPSecret, m.LastUsedStep-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.verifyTOTP(ctx, m, prev); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("langkah lama: err = %v, want ErrInvalidMFACode", err)
+	}
+}
@@ -2,13 +2,90 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Port        string
 	DatabaseURL string
-	JWTSecret   string
+	// TrustedProxies adalah IP/CIDR reverse proxy yang header X-Forwarded-For-nya
+	// dipercaya untuk menentukan IP client. Kosong berarti IP diambil dari koneksi.
+	TrustedProxies []string
+	JWTSecret      string
+	// JWTPrivateKeyFiles (kid -> path PEM) mengaktifkan RS256/EdDSA; kosong berarti HS256.
+	// JWTPublicKeyFiles untuk key lama yang hanya dipakai verifikasi.
+	JWTPrivateKeyFiles   map[string]string
+	JWTPublicKeyFiles    map[string]string
+	JWTActiveKID         string
+	JWTAcceptLegacyHS256 bool
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+
+	// AppBaseURL adalah URL frontend, dipakai untuk link di email.
+	AppBaseURL           string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	// RequireEmailVerification membuat login menolak akun yang emailnya belum diverifikasi.
+	RequireEmailVerification bool
+	// AllowSelfRegistration false menutup /api/register; akun baru hanya lewat undangan Admin.
+	AllowSelfRegistration bool
+	InvitationTTL         time.Duration
+
+	// Proteksi brute-force: setelah N kegagalan, key dikunci selama LoginLockoutBase
+	// yang berlipat dua setiap kegagalan berikutnya, maksimal LoginLockoutMax.
+	// Hitungan dimulai ulang bila tidak ada kegagalan selama LoginFailureWindow.
+	LoginMaxFailuresPerEmail int
+	LoginMaxFailuresPerIP    int
+	LoginLockoutBase         time.Duration
+	LoginLockoutMax          time.Duration
+	LoginFailureWindow       time.Duration
+
+	// Kebijakan password baru
+	PasswordMinLength     int
+	PasswordRequireUpper  bool
+	PasswordRequireLower  bool
+	PasswordRequireDigit  bool
+	PasswordRequireSymbol bool
+	PasswordRejectCommon  bool
+
+	// PasswordHashAlgorithm "bcrypt" atau "argon2id". Hash lama di-upgrade saat login
+	// bila algoritma atau parameternya berbeda.
+	PasswordHashAlgorithm string
+	BcryptCost            int
+	Argon2MemoryKB        int
+	Argon2Iterations      int
+	Argon2Parallelism     int
+
+	MFAIssuer string
+	// MFARequiredForAdmin menolak akses endpoint Admin dari sesi tanpa verifikasi MFA.
+	MFARequiredForAdmin bool
+
+	// OIDC aktif bila OIDCIssuer diisi.
+	OIDCIssuer        string
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCRedirectURL   string
+	OIDCScopes        []string
+	OIDCAutoProvision bool
+	// OIDCAllowUnverifiedEmail mengizinkan auto-provision dari ID token tanpa claim
+	// email_verified. Akun yang sudah ada tetap hanya ditautkan bila email_verified=true.
+	OIDCAllowUnverifiedEmail bool
+	// OIDCHTTPClient tidak dibaca dari env; diisi test untuk memakai mock IdP lokal.
+	OIDCHTTPClient *http.Client
+
+	MailDriver   string
+	MailFrom     string
+	MailFileDir  string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
 }
 
 func Load() (*Config, error) {
@@ -17,6 +94,11 @@ func Load() (*Config, error) {
 		port = "8080"
 	}
 
+	trustedProxies, err := proxiesEnv("TRUSTED_PROXIES")
+	if err != nil {
+		return nil, err
+	}
+
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
 		return nil, errors.New("DATABASE_URL tidak ditemukan di environment/.env")
@@ -27,11 +109,277 @@ func Load() (*Config, error) {
 		jwtSecret = "dev-secret-change-me"
 	}
 
+	privateKeys, err := keyFilesEnv("JWT_PRIVATE_KEY_FILES")
+	if err != nil {
+		return nil, err
+	}
+	publicKeys, err := keyFilesEnv("JWT_PUBLIC_KEY_FILES")
+	if err != nil {
+		return nil, err
+	}
+	activeKID := os.Getenv("JWT_ACTIVE_KID")
+	if len(privateKeys) == 1 && activeKID == "" {
+		for kid := range privateKeys {
+			activeKID = kid
+		}
+	}
+	if len(privateKeys) > 1 && activeKID == "" {
+		return nil, errors.New("JWT_ACTIVE_KID wajib diisi bila JWT_PRIVATE_KEY_FILES berisi lebih dari satu key")
+	}
+	acceptLegacy, err := boolEnv("JWT_ACCEPT_LEGACY_HS256", false)
+	if err != nil {
+		return nil, err
+	}
+
+	accessTTL, err := durationEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	refreshTTL, err := durationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	resetTTL, err := durationEnv("PASSWORD_RESET_TTL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	verifyTTL, err := durationEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	requireVerification, err := boolEnv("REQUIRE_EMAIL_VERIFICATION", false)
+	if err != nil {
+		return nil, err
+	}
+
+	allowRegistration, err := boolEnv("ALLOW_SELF_REGISTRATION", true)
+	if err != nil {
+		return nil, err
+	}
+	invitationTTL, err := durationEnv("INVITATION_TTL", 7*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	minLength, err := intEnv("PASSWORD_MIN_LENGTH", 8)
+	if err != nil {
+		return nil, err
+	}
+	requireUpper, err := boolEnv("PASSWORD_REQUIRE_UPPER", false)
+	if err != nil {
+		return nil, err
+	}
+	requireLower, err := boolEnv("PASSWORD_REQUIRE_LOWER", false)
+	if err != nil {
+		return nil, err
+	}
+	requireDigit, err := boolEnv("PASSWORD_REQUIRE_DIGIT", false)
+	if err != nil {
+		return nil, err
+	}
+	requireSymbol, err := boolEnv("PASSWORD_REQUIRE_SYMBOL", false)
+	if err != nil {
+		return nil, err
+	}
+	rejectCommon, err := boolEnv("PASSWORD_REJECT_COMMON", true)
+	if err != nil {
+		return nil, err
+	}
+	hashAlgorithm := stringEnv("PASSWORD_HASH_ALGORITHM", "bcrypt")
+	if hashAlgorithm != "bcrypt" && hashAlgorithm != "argon2id" {
+		return nil, fmt.Errorf("PASSWORD_HASH_ALGORITHM tidak valid: %q", hashAlgorithm)
+	}
+	bcryptCost, err := intEnv("BCRYPT_COST", 10)
+	if err != nil {
+		return nil, err
+	}
+	if bcryptCost < 4 || bcryptCost > 31 {
+		return nil, fmt.Errorf("BCRYPT_COST tidak valid: %d", bcryptCost)
+	}
+	argonMemory, err := intEnv("ARGON2_MEMORY_KB", 64*1024)
+	if err != nil {
+		return nil, err
+	}
+	argonIterations, err := intEnv("ARGON2_ITERATIONS", 3)
+	if err != nil {
+		return nil, err
+	}
+	argonParallelism, err := intEnv("ARGON2_PARALLELISM", 2)
+	if err != nil {
+		return nil, err
+	}
+	if argonParallelism > 255 {
+		return nil, fmt.Errorf("ARGON2_PARALLELISM tidak valid: %d", argonParallelism)
+	}
+
+	mfaRequiredForAdmin, err := boolEnv("MFA_REQUIRED_FOR_ADMIN", false)
+	if err != nil {
+		return nil, err
+	}
+	maxPerEmail, err := intEnv("LOGIN_MAX_FAILURES_PER_EMAIL", 5)
+	if err != nil {
+		return nil, err
+	}
+	maxPerIP, err := intEnv("LOGIN_MAX_FAILURES_PER_IP", 20)
+	if err != nil {
+		return nil, err
+	}
+	lockoutBase, err := durationEnv("LOGIN_LOCKOUT_BASE", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	lockoutMax, err := durationEnv("LOGIN_LOCKOUT_MAX", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	failureWindow, err := durationEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	oidcAutoProvision, err := boolEnv("OIDC_AUTO_PROVISION", true)
+	if err != nil {
+		return nil, err
+	}
+	oidcAllowUnverified, err := boolEnv("OIDC_ALLOW_UNVERIFIED_EMAIL", false)
+	if err != nil {
+		return nil, err
+	}
+	oidcIssuer := os.Getenv("OIDC_ISSUER")
+	if oidcIssuer != "" && (os.Getenv("OIDC_CLIENT_ID") == "" || os.Getenv("OIDC_REDIRECT_URL") == "") {
+		return nil, errors.New("OIDC_CLIENT_ID dan OIDC_REDIRECT_URL wajib diisi bila OIDC_ISSUER diset")
+	}
+
 	return &Config{
-		Port:        port,
-		DatabaseURL: dbURL,
-		JWTSecret:   jwtSecret,
+		Port:                     port,
+		DatabaseURL:              dbURL,
+		TrustedProxies:           trustedProxies,
+		JWTSecret:                jwtSecret,
+		JWTPrivateKeyFiles:       privateKeys,
+		JWTPublicKeyFiles:        publicKeys,
+		JWTActiveKID:             activeKID,
+		JWTAcceptLegacyHS256:     acceptLegacy,
+		AccessTokenTTL:           accessTTL,
+		RefreshTokenTTL:          refreshTTL,
+		AppBaseURL:               stringEnv("APP_BASE_URL", "http://localhost:3000"),
+		PasswordResetTTL:         resetTTL,
+		EmailVerificationTTL:     verifyTTL,
+		RequireEmailVerification: requireVerification,
+		AllowSelfRegistration:    allowRegistration,
+		InvitationTTL:            invitationTTL,
+		LoginMaxFailuresPerEmail: maxPerEmail,
+		LoginMaxFailuresPerIP:    maxPerIP,
+		LoginLockoutBase:         lockoutBase,
+		LoginLockoutMax:          lockoutMax,
+		LoginFailureWindow:       failureWindow,
+		PasswordMinLength:        minLength,
+		PasswordRequireUpper:     requireUpper,
+		PasswordRequireLower:     requireLower,
+		PasswordRequireDigit:     requireDigit,
+		PasswordRequireSymbol:    requireSymbol,
+		PasswordRejectCommon:     rejectCommon,
+		PasswordHashAlgorithm:    hashAlgorithm,
+		BcryptCost:               bcryptCost,
+		Argon2MemoryKB:           argonMemory,
+		Argon2Iterations:         argonIterations,
+		Argon2Parallelism:        argonParallelism,
+		MFAIssuer:                stringEnv("MFA_ISSUER", "Workmate"),
+		MFARequiredForAdmin:      mfaRequiredForAdmin,
+		OIDCIssuer:               oidcIssuer,
+		OIDCClientID:             os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret:         os.Getenv("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:          os.Getenv("OIDC_REDIRECT_URL"),
+		OIDCScopes:               strings.Fields(stringEnv("OIDC_SCOPES", "openid email profile")),
+		OIDCAutoProvision:        oidcAutoProvision,
+		OIDCAllowUnverifiedEmail: oidcAllowUnverified,
+		MailDriver:               stringEnv("MAIL_DRIVER", "log"),
+		MailFrom:                 stringEnv("MAIL_FROM", "Workmate <no-reply@workmate.local>"),
+		MailFileDir:              stringEnv("MAIL_FILE_DIR", "tmp/mail"),
+		SMTPHost:                 os.Getenv("SMTP_HOST"),
+		SMTPPort:                 stringEnv("SMTP_PORT", "587"),
+		SMTPUsername:             os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:             os.Getenv("SMTP_PASSWORD"),
 	}, nil
 }
 
+func stringEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func boolEnv(key string, def bool) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s tidak valid: %q", key, v)
+	}
+	return b, nil
+}
+
+func intEnv(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s tidak valid: %q", key, v)
+	}
+	return n, nil
+}
+
+// keyFilesEnv membaca daftar "kid=path,kid=path" dari env.
+func keyFilesEnv(key string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(item, "=")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("%s tidak valid: %q (format kid=path)", key, item)
+		}
+		out[kid] = path
+	}
+	return out, nil
+}
+
+// proxiesEnv membaca daftar IP atau CIDR dipisah koma dari env.
+func proxiesEnv(key string) ([]string, error) {
+	var out []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if net.ParseIP(item) == nil {
+			if _, _, err := net.ParseCIDR(item); err != nil {
+				return nil, fmt.Errorf("%s tidak valid: %q (harus IP atau CIDR)", key, item)
+			}
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// durationEnv membaca durasi (format time.ParseDuration, mis. "15m") dari env.
+func durationEnv(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s tidak valid: %q", key, v)
+	}
+	return d, nil
+}
+
 // end
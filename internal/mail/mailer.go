@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	"backend-work-mate/internal/config"
+)
+
+// Message adalah email teks sederhana.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer mengirim email. Implementasi dipilih lewat config.MailDriver.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New membuat Mailer sesuai MAIL_DRIVER: "smtp", "file", atau "log" (default).
+func New(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "", "log":
+		return &LogMailer{From: cfg.MailFrom}, nil
+	case "file":
+		return &FileMailer{Dir: cfg.MailFileDir, From: cfg.MailFrom}, nil
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST wajib diisi untuk MAIL_DRIVER=smtp")
+		}
+		return &SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}, nil
+	default:
+		return nil, fmt.Errorf("MAIL_DRIVER tidak dikenal: %q", cfg.MailDriver)
+	}
+}
@@ -0,0 +1,163 @@
+// Package oidctest menyediakan mock IdP OIDC lokal (discovery, JWKS, token
+// endpoint dengan PKCE) untuk menguji alur login SSO tanpa IdP sungguhan.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Server adalah mock IdP. Key RSA dengan kid KID dipublikasikan di JWKS.
+type Server struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+	KID          string
+	Key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+type grant struct {
+	challenge string
+	idToken   string
+}
+
+// NewServer menjalankan mock IdP yang ditutup otomatis di akhir test.
+func NewServer(t *testing.T, clientID, clientSecret string) *Server {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{ClientID: clientID, ClientSecret: clientSecret, KID: "test-key", Key: key, codes: map[string]grant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/jwks", s.jwks)
+	mux.HandleFunc("/token", s.token)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Claims mengembalikan claim ID token valid untuk nonce; test bisa mengubahnya
+// sebelum memanggil Authorize atau SignIDToken.
+func (s *Server) Claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            s.URL,
+		"aud":            s.ClientID,
+		"sub":            "idp-user-1",
+		"email":          "sso@example.com",
+		"email_verified": true,
+		"name":           "SSO User",
+		"nonce":          nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	}
+}
+
+// SignIDToken menandatangani claims dengan RS256 memakai kid yang diberikan.
+func (s *Server) SignIDToken(t *testing.T, claims jwt.MapClaims, kid string) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = kid
+	signed, err := tok.SignedString(s.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// Authorize meniru user yang menyetujui login di IdP: membaca state, nonce dan
+// code_challenge dari URL authorization, lalu mengembalikan authorization code
+// dan state. edit (boleh nil) dapat mengubah claim ID token sebelum ditandatangani.
+func (s *Server) Authorize(t *testing.T, authURL string, edit func(jwt.MapClaims)) (code, state string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("client_id") != s.ClientID {
+		t.Fatalf("authorization request tidak valid: %s", authURL)
+	}
+	claims := s.Claims(q.Get("nonce"))
+	if edit != nil {
+		edit(claims)
+	}
+	idToken := s.SignIDToken(t, claims, s.KID)
+	s.mu.Lock()
+	code = fmt.Sprintf("code-%d", len(s.codes)+1)
+	s.codes[code] = grant{challenge: q.Get("code_challenge"), idToken: idToken}
+	s.mu.Unlock()
+	return code, q.Get("state")
+}
+
+func (s *Server) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, _ *http.Request) {
+	pub := s.Key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": s.KID,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+// token hanya menerima code sekali pakai dengan code_verifier yang cocok (S256)
+// dan autentikasi client yang benar.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id = r.Form.Get("client_id")
+	}
+	if id != s.ClientID || secret != s.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	s.mu.Lock()
+	g, found := s.codes[r.Form.Get("code")]
+	delete(s.codes, r.Form.Get("code"))
+	s.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if !found || base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": "idp-access-token", "token_type": "Bearer", "id_token": g.idToken})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,168 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"backend-work-mate/internal/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Server) {
+	t.Helper()
+	idp := oidctest.NewServer(t, "workmate", "secret")
+	p := NewProvider(Config{
+		Issuer:       idp.URL + "/",
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://localhost:3000/callback",
+		HTTPClient:   idp.Client(),
+	})
+	return p, idp
+}
+
+func TestDiscoveryAndAuthCodeURL(t *testing.T) {
+	p, idp := newTestProvider(t)
+	raw, err := p.AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != idp.URL+"/authorize" {
+		t.Errorf("authorization endpoint = %s", got)
+	}
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "workmate",
+		"redirect_uri":          "http://localhost:3000/callback",
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if got := u.Query().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	idp := oidctest.NewServer(t, "workmate", "")
+	p := NewProvider(Config{Issuer: idp.URL + "/tenant", ClientID: "workmate", HTTPClient: idp.Client()})
+	_, err := p.AuthCodeURL(context.Background(), "s", "n", "c")
+	if err == nil {
+		t.Fatal("discovery dari issuer lain diterima")
+	}
+}
+
+func TestExchangeWithPKCE(t *testing.T) {
+	p, idp := newTestProvider(t)
+	ctx := context.Background()
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, _ := idp.Authorize(t, authURL, nil)
+	if _, err := p.Exchange(ctx, code, "verifier-lain"); err == nil {
+		t.Fatal("exchange dengan code_verifier salah berhasil")
+	}
+
+	code, _ = idp.Authorize(t, authURL, nil)
+	tokens, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	claims, err := p.VerifyIDToken(ctx, tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.Email != "sso@example.com" || claims.EmailVerified == nil || !*claims.EmailVerified {
+		t.Errorf("claims = %+v", claims)
+	}
+	if _, err := p.Exchange(ctx, code, verifier); err == nil {
+		t.Fatal("authorization code bisa dipakai dua kali")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	p, idp := newTestProvider(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		edit  func(jwt.MapClaims)
+		kid   string
+		sign  func(jwt.MapClaims, string) string
+		nonce string
+		want  string
+	}{
+		{name: "valid", nonce: "n1"},
+		{name: "nonce salah", nonce: "n2", want: "nonce"},
+		{name: "tanpa nonce", edit: func(c jwt.MapClaims) { delete(c, "nonce") }, nonce: "", want: "nonce"},
+		{name: "aud salah", edit: func(c jwt.MapClaims) { c["aud"] = "aplikasi-lain" }, nonce: "n1", want: "audience"},
+		{name: "iss salah", edit: func(c jwt.MapClaims) { c["iss"] = "https://idp.evil.example" }, nonce: "n1", want: "issuer"},
+		{name: "kedaluwarsa", edit: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-10 * time.Minute).Unix() }, nonce: "n1", want: "expired"},
+		{name: "tanpa exp", edit: func(c jwt.MapClaims) { delete(c, "exp") }, nonce: "n1", want: "exp"},
+		{name: "kid tidak dikenal", kid: "kid-lain", nonce: "n1", want: "kid"},
+		{name: "key lain dengan kid sama", nonce: "n1", want: "signature",
+			sign: func(c jwt.MapClaims, kid string) string {
+				tok := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+				tok.Header["kid"] = kid
+				s, _ := tok.SignedString(otherKey)
+				return s
+			}},
+		{name: "alg HS256", nonce: "n1", want: "signing method",
+			sign: func(c jwt.MapClaims, kid string) string {
+				tok := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
+				tok.Header["kid"] = kid
+				s, _ := tok.SignedString([]byte("secret"))
+				return s
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := idp.Claims("n1")
+			if tt.edit != nil {
+				tt.edit(claims)
+			}
+			kid := idp.KID
+			if tt.kid != "" {
+				kid = tt.kid
+			}
+			var raw string
+			if tt.sign != nil {
+				raw = tt.sign(claims, kid)
+			} else {
+				raw = idp.SignIDToken(t, claims, kid)
+			}
+			_, err := p.VerifyIDToken(context.Background(), raw, tt.nonce)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("err = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want mengandung %q", err, tt.want)
+			}
+		})
+	}
+}
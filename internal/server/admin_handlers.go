@@ -0,0 +1,305 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend-work-mate/internal/auth"
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type AdminUpdateUserInput struct {
+	Name       *string `json:"name"`
+	Role       *string `json:"role" binding:"omitempty,oneof=Admin Employee"`
+	Department *string `json:"department"`
+}
+
+// Admin List Tasks godoc
+// @Summary List task semua user (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param user_id query string false "Filter berdasarkan pemilik task"
+// @Param status query []string false "Filter status (boleh berulang atau dipisah koma)" collectionFormat(multi)
+// @Param due_from query string false "Due date mulai (RFC3339 atau YYYY-MM-DD)"
+// @Param due_to query string false "Due date sampai (RFC3339 atau YYYY-MM-DD)"
+// @Param overdue query bool false "Hanya task yang lewat due date dan belum Done/Cancelled"
+// @Param created_from query string false "Dibuat mulai"
+// @Param created_to query string false "Dibuat sampai"
+// @Param updated_from query string false "Diubah mulai"
+// @Param updated_to query string false "Diubah sampai"
+// @Param priority query []string false "Filter priority: Low, Medium, High, Urgent" collectionFormat(multi)
+// @Param label query []string false "Filter label ID (minimal salah satu)" collectionFormat(multi)
+// @Param q query string false "Cari di title dan description"
+// @Param sort query string false "created_at (default), updated_at, due_date, title, priority"
+// @Param order query string false "asc atau desc"
+// @Param limit query int false "Jumlah data per halaman (default 20, maks 100)"
+// @Param cursor query string false "next_cursor/prev_cursor dari response sebelumnya"
+// @Param include_total query bool false "Sertakan total data yang cocok dengan filter"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/admin/tasks [get]
+func (h *Handlers) AdminListTasks(c *gin.Context) {
+	f, err := parseTaskFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	items, info, err := h.TaskRepo.ListAll(c.Request.Context(), c.Query("user_id"), f, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items, "pagination": info})
+}
+
+// Admin Get Task godoc
+// @Summary Detail task milik user mana pun (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/admin/tasks/{id} [get]
+func (h *Handlers) AdminGetTask(c *gin.Context) {
+	t, err := h.TaskRepo.GetAnyByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": t})
+}
+
+// Admin List Users godoc
+// @Summary List dan cari user (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param q query string false "Cari berdasarkan nama atau email"
+// @Param role query string false "Admin atau Employee"
+// @Param department query string false "Filter department"
+// @Param active query bool false "Filter status aktif"
+// @Param limit query int false "Jumlah data (maks 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/admin/users [get]
+func (h *Handlers) AdminListUsers(c *gin.Context) {
+	f := postgres.UserFilter{Query: c.Query("q")}
+	if v := c.Query("role"); v != "" {
+		role := postgres.UserRole(v)
+		if role != postgres.RoleAdmin && role != postgres.RoleEmployee {
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "invalid role", "allowed": []postgres.UserRole{postgres.RoleAdmin, postgres.RoleEmployee}})
+			return
+		}
+		f.Role = &role
+	}
+	if v, ok := c.GetQuery("department"); ok {
+		f.Department = &v
+	}
+	if v := c.Query("active"); v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "invalid active filter"})
+			return
+		}
+		f.Active = &active
+	}
+	limit := queryInt(c, "limit", 20)
+	offset := queryInt(c, "offset", 0)
+	users, total, err := h.UserRepo.List(c.Request.Context(), f, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": users, "total": total})
+}
+
+// Admin Get User godoc
+// @Summary Detail user (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/admin/users/{id} [get]
+func (h *Handlers) AdminGetUser(c *gin.Context) {
+	user, err := h.UserRepo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": user})
+}
+
+// Admin Update User godoc
+// @Summary Update nama, role, atau department user (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body AdminUpdateUserInput true "User update"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/admin/users/{id} [patch]
+func (h *Handlers) AdminUpdateUser(c *gin.Context) {
+	var in AdminUpdateUserInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	user, err := h.UserRepo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	if in.Name != nil {
+		if *in.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "name tidak boleh kosong"})
+			return
+		}
+		user.Name = *in.Name
+	}
+	if in.Role != nil {
+		if user.ID == c.GetString("user_id") && postgres.UserRole(*in.Role) != user.Role {
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "tidak bisa mengubah role sendiri"})
+			return
+		}
+		user.Role = postgres.UserRole(*in.Role)
+	}
+	if in.Department != nil {
+		if *in.Department == "" {
+			user.Department = nil
+		} else {
+			user.Department = in.Department
+		}
+	}
+	if err := h.UserRepo.Update(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": user})
+}
+
+// Admin Deactivate User godoc
+// @Summary Nonaktifkan user dan cabut semua token-nya (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/admin/users/{id}/deactivate [post]
+func (h *Handlers) AdminDeactivateUser(c *gin.Context) {
+	h.adminSetUserActive(c, false)
+}
+
+// Admin Reactivate User godoc
+// @Summary Aktifkan kembali user (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/admin/users/{id}/reactivate [post]
+func (h *Handlers) AdminReactivateUser(c *gin.Context) {
+	h.adminSetUserActive(c, true)
+}
+
+func (h *Handlers) adminSetUserActive(c *gin.Context, active bool) {
+	id := c.Param("id")
+	if !active && id == c.GetString("user_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "tidak bisa menonaktifkan akun sendiri"})
+		return
+	}
+	if err := h.UserRepo.SetActive(c.Request.Context(), id, active); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	if !active {
+		if err := h.AuthSvc.LogoutAll(c.Request.Context(), id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "id": id, "is_active": active})
+}
+
+// Admin Unlock User godoc
+// @Summary Buka kunci login akun setelah terlalu banyak percobaan gagal (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/admin/users/{id}/unlock [post]
+func (h *Handlers) AdminUnlockUser(c *gin.Context) {
+	if err := h.AuthSvc.UnlockUser(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "unlocked"})
+}
+
+// Admin Delete User godoc
+// @Summary Hapus user beserta task-nya (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/admin/users/{id} [delete]
+func (h *Handlers) AdminDeleteUser(c *gin.Context) {
+	id := c.Param("id")
+	if id == c.GetString("user_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "tidak bisa menghapus akun sendiri"})
+		return
+	}
+	if err := h.UserRepo.Delete(c.Request.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "deleted"})
+}
+
+// queryInt membaca query parameter integer, mengembalikan def bila kosong atau tidak valid.
+func queryInt(c *gin.Context, key string, def int) int {
+	v, err := strconv.Atoi(c.Query(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
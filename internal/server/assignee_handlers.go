@@ -0,0 +1,107 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type AssignTaskInput struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// Assign Task godoc
+// @Summary Tugaskan task ke user lain
+// @Description Hanya pembuat task yang bisa menambah assignee. Assignee bisa melihat dan mengubah task.
+// @Tags Tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body AssignTaskInput true "User yang ditugaskan"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/tasks/{id}/assignees [post]
+func (h *Handlers) AssignTask(c *gin.Context) {
+	var in AssignTaskInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	uid := c.GetString("user_id")
+	t, ok := h.ownedTask(c, uid, c.Param("id"))
+	if !ok {
+		return
+	}
+	u, err := h.UserRepo.GetByID(c.Request.Context(), in.UserID)
+	if err != nil || u == nil || !u.IsActive {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "user not found"})
+		return
+	}
+	if err := h.TaskRepo.AddAssignee(c.Request.Context(), t.ID, u.ID, uid); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	h.respondTask(c, uid, t.ID)
+}
+
+// Unassign Task godoc
+// @Summary Lepas assignee dari task
+// @Description Pembuat task bisa melepas siapa saja; assignee hanya bisa melepas dirinya sendiri.
+// @Tags Tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param user_id path string true "User ID assignee"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/tasks/{id}/assignees/{user_id} [delete]
+func (h *Handlers) UnassignTask(c *gin.Context) {
+	uid := c.GetString("user_id")
+	target := c.Param("user_id")
+	t, err := h.TaskRepo.GetByID(c.Request.Context(), uid, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	if t.UserID != uid && target != uid {
+		c.JSON(http.StatusForbidden, gin.H{"response_code": http.StatusForbidden, "error": "forbidden"})
+		return
+	}
+	if err := h.TaskRepo.RemoveAssignee(c.Request.Context(), t.ID, target); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	if t.UserID != uid {
+		// assignee yang melepas dirinya tidak lagi bisa melihat task
+		c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "unassigned"})
+		return
+	}
+	h.respondTask(c, uid, t.ID)
+}
+
+// ownedTask mengambil task yang terlihat oleh user dan menulis 404/403 bila
+// user bukan pembuatnya. Aksi seperti hapus, label dan assign hanya untuk pembuat.
+func (h *Handlers) ownedTask(c *gin.Context, userID, id string) (*postgres.Task, bool) {
+	t, err := h.TaskRepo.GetByID(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return nil, false
+	}
+	if t.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"response_code": http.StatusForbidden, "error": "forbidden"})
+		return nil, false
+	}
+	return t, true
+}
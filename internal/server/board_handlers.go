@@ -0,0 +1,104 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type MoveTaskInput struct {
+	Status postgres.TaskStatus `json:"status" binding:"required"`
+	// PrevID task yang berada tepat di atas posisi tujuan, NextID tepat di bawahnya.
+	// Keduanya kosong berarti taruh di akhir kolom.
+	PrevID string `json:"prev_id"`
+	NextID string `json:"next_id"`
+}
+
+// Task Board godoc
+// @Summary Board kanban: kolom per status berisi task terurut
+// @Description Menerima filter yang sama dengan GET /api/tasks (kecuali sort dan cursor).
+// @Tags Tasks
+// @Security BearerAuth
+// @Produce json
+// @Param project_id query string false "Board untuk satu project"
+// @Param view query string false "assigned, created, atau kosong = semua"
+// @Param status query []string false "Kolom yang ditampilkan" collectionFormat(multi)
+// @Param limit query int false "Jumlah task per kolom (default 100, maks 100)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/tasks/board [get]
+func (h *Handlers) TaskBoard(c *gin.Context) {
+	f, err := parseTaskFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	limit := postgres.MaxPageSize
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "invalid limit"})
+			return
+		}
+		limit = min(n, postgres.MaxPageSize)
+	}
+	columns, err := h.TaskRepo.Board(c.Request.Context(), c.GetString("user_id"), f, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": columns})
+}
+
+// Move Task godoc
+// @Summary Pindahkan task di board (ubah urutan dan/atau status)
+// @Description Perpindahan status mengikuti aturan yang sama dengan /transition.
+// @Tags Tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body MoveTaskInput true "Kolom tujuan dan task tetangga"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/tasks/{id}/move [post]
+func (h *Handlers) MoveTask(c *gin.Context) {
+	var in MoveTaskInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	uid := c.GetString("user_id")
+	t, err := h.TaskRepo.GetByID(c.Request.Context(), uid, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	if in.PrevID == t.ID || in.NextID == t.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": postgres.ErrInvalidNeighbor.Error()})
+		return
+	}
+	if !respondTransition(c, t.Status, in.Status) || !h.respondOpenBlockers(c, uid, t, in.Status) {
+		return
+	}
+	moved, err := h.TaskRepo.Move(c.Request.Context(), uid, t, in.Status, in.PrevID, in.NextID)
+	if err != nil {
+		switch {
+		case errors.Is(err, postgres.ErrInvalidNeighbor):
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		case errors.Is(err, pgx.ErrNoRows):
+			c.JSON(http.StatusConflict, gin.H{"response_code": http.StatusConflict, "error": "status task sudah berubah, muat ulang task"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": moved})
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type CreateChecklistItemInput struct {
+	Title string `json:"title" binding:"required,max=200"`
+	Done  bool   `json:"done"`
+}
+
+type UpdateChecklistItemInput struct {
+	Title *string `json:"title" binding:"omitempty,min=1,max=200"`
+	Done  *bool   `json:"done"`
+}
+
+// Add Checklist Item godoc
+// @Summary Tambah item checklist ke task
+// @Tags Tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body CreateChecklistItemInput true "Item checklist"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/tasks/{id}/checklist [post]
+func (h *Handlers) AddChecklistItem(c *gin.Context) {
+	var in CreateChecklistItemInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	uid := c.GetString("user_id")
+	t, err := h.TaskRepo.GetByID(c.Request.Context(), uid, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	item := &postgres.ChecklistItem{TaskID: t.ID, Title: in.Title, Done: in.Done}
+	if err := h.ChecklistRepo.Create(c.Request.Context(), item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	h.respondTask(c, uid, t.ID)
+}
+
+// Update Checklist Item godoc
+// @Summary Ubah atau centang item checklist
+// @Tags Tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param item_id path string true "Checklist item ID"
+// @Param request body UpdateChecklistItemInput true "Field yang diubah"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/tasks/{id}/checklist/{item_id} [patch]
+func (h *Handlers) UpdateChecklistItem(c *gin.Context) {
+	var in UpdateChecklistItemInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	uid := c.GetString("user_id")
+	t, err := h.TaskRepo.GetByID(c.Request.Context(), uid, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	item, err := h.ChecklistRepo.GetByID(c.Request.Context(), t.ID, c.Param("item_id"))
+	if err != nil || item == nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	if in.Title != nil {
+		item.Title = *in.Title
+	}
+	if in.Done != nil {
+		item.Done = *in.Done
+	}
+	if err := h.ChecklistRepo.Update(c.Request.Context(), item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	h.respondTask(c, uid, t.ID)
+}
+
+// Delete Checklist Item godoc
+// @Summary Hapus item checklist
+// @Tags Tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param item_id path string true "Checklist item ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/tasks/{id}/checklist/{item_id} [delete]
+func (h *Handlers) DeleteChecklistItem(c *gin.Context) {
+	uid := c.GetString("user_id")
+	t, err := h.TaskRepo.GetByID(c.Request.Context(), uid, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	if err := h.ChecklistRepo.Delete(c.Request.Context(), t.ID, c.Param("item_id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	h.respondTask(c, uid, t.ID)
+}
+
+// respondTaskParent memvalidasi parent subtask: harus terlihat oleh user dan dibuat
+// oleh user yang sama, tidak membuat siklus dan tidak melebihi kedalaman maksimum.
+// Hanya pembuat task yang boleh mengatur parent, sehingga userID juga pembuat
+// subtask-nya. taskID kosong untuk task baru. Menulis 400 dan mengembalikan false
+// bila tidak valid.
+func (h *Handlers) respondTaskParent(c *gin.Context, userID, taskID, parentID string) bool {
+	parent, err := h.TaskRepo.GetByID(c.Request.Context(), userID, parentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "parent task not found"})
+		return false
+	}
+	if parent.UserID != userID {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "parent task harus dibuat oleh pembuat subtask"})
+		return false
+	}
+	if err := h.TaskRepo.CheckParent(c.Request.Context(), taskID, parentID); err != nil {
+		resp := gin.H{"response_code": http.StatusBadRequest, "error": err.Error()}
+		if errors.Is(err, postgres.ErrTaskTooDeep) {
+			resp["max_depth"] = postgres.MaxTaskDepth
+		}
+		c.JSON(http.StatusBadRequest, resp)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"backend-work-mate/internal/storage/postgres"
+)
+
+func parentHandlers() (*Handlers, *fakeTasks) {
+	ptr := func(s string) *string { return &s }
+	tasks := &fakeTasks{
+		byID: map[string]*postgres.Task{
+			// root <- child <- grandchild milik u1
+			"root":       {ID: "root", UserID: "u1", Status: postgres.TaskStatusTodo},
+			"child":      {ID: "child", UserID: "u1", ParentID: ptr("root"), Status: postgres.TaskStatusTodo},
+			"grandchild": {ID: "grandchild", UserID: "u1", ParentID: ptr("child"), Status: postgres.TaskStatusTodo},
+			"solo":       {ID: "solo", UserID: "u1", Status: postgres.TaskStatusTodo},
+			// shared milik u2 tetapi terlihat oleh u1 (mis. project yang sama)
+			"shared": {ID: "shared", UserID: "u2", Status: postgres.TaskStatusTodo},
+			"hidden": {ID: "hidden", UserID: "u2", Status: postgres.TaskStatusTodo},
+		},
+		visible: map[string][]string{"u1": {"shared"}},
+	}
+	return &Handlers{TaskRepo: tasks}, tasks
+}
+
+func setParent(t *testing.T, h *Handlers, taskID, parentID string) (int, map[string]any) {
+	t.Helper()
+	return serve(t, http.MethodPut, "/tasks/:id", "/tasks/"+taskID, "u1",
+		`{"parent_id":"`+parentID+`"}`, h.UpdateTask)
+}
+
+func TestUpdateTaskParentRules(t *testing.T) {
+	tests := []struct {
+		name, task, parent string
+		code               int
+		err                string
+	}{
+		{"parent milik user lain", "solo", "shared", http.StatusBadRequest, "parent task harus dibuat oleh pembuat subtask"},
+		{"parent tidak terlihat", "solo", "hidden", http.StatusBadRequest, "parent task not found"},
+		{"parent diri sendiri", "solo", "solo", http.StatusBadRequest, postgres.ErrTaskCycle.Error()},
+		{"parent anak langsung", "root", "child", http.StatusBadRequest, postgres.ErrTaskCycle.Error()},
+		{"parent cucu", "root", "grandchild", http.StatusBadRequest, postgres.ErrTaskCycle.Error()},
+		{"melebihi kedalaman", "solo", "grandchild", http.StatusBadRequest, postgres.ErrTaskTooDeep.Error()},
+		{"task milik user lain", "shared", "solo", http.StatusForbidden, "forbidden"},
+		{"parent valid", "solo", "child", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		h, tasks := parentHandlers()
+		before := tasks.byID[tt.task].ParentID
+		code, body := setParent(t, h, tt.task, tt.parent)
+		if code != tt.code {
+			t.Errorf("%s: code = %d, ingin %d: %v", tt.name, code, tt.code, body)
+			continue
+		}
+		if tt.err != "" {
+			if body["error"] != tt.err {
+				t.Errorf("%s: error = %v, ingin %q", tt.name, body["error"], tt.err)
+			}
+			if tasks.byID[tt.task].ParentID != before {
+				t.Errorf("%s: parent berubah meski ditolak", tt.name)
+			}
+			continue
+		}
+		if p := tasks.byID[tt.task].ParentID; p == nil || *p != tt.parent {
+			t.Errorf("%s: parent = %v, ingin %s", tt.name, p, tt.parent)
+		}
+	}
+}
+
+func TestUpdateTaskParentTooDeepReportsMaxDepth(t *testing.T) {
+	h, _ := parentHandlers()
+	_, body := setParent(t, h, "solo", "grandchild")
+	if body["max_depth"] != float64(postgres.MaxTaskDepth) {
+		t.Fatalf("max_depth = %v, ingin %d", body["max_depth"], postgres.MaxTaskDepth)
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type AddDependencyInput struct {
+	DependsOnID string `json:"depends_on_id" binding:"required"`
+}
+
+// Add Task Dependency godoc
+// @Summary Tandai task diblokir oleh task lain
+// @Description Task tidak bisa dipindah ke Done selama task yang memblokirnya belum Done/Cancelled.
+// @Tags Tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body AddDependencyInput true "Task yang memblokir"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/tasks/{id}/dependencies [post]
+func (h *Handlers) AddTaskDependency(c *gin.Context) {
+	var in AddDependencyInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	uid := c.GetString("user_id")
+	t, err := h.TaskRepo.GetByID(c.Request.Context(), uid, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	blocker, err := h.TaskRepo.GetByID(c.Request.Context(), uid, in.DependsOnID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "blocking task not found"})
+		return
+	}
+	if err := h.TaskRepo.AddDependency(c.Request.Context(), t.ID, blocker.ID, uid); err != nil {
+		if errors.Is(err, postgres.ErrDependencyCycle) {
+			c.JSON(http.StatusConflict, gin.H{"response_code": http.StatusConflict, "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	h.respondTask(c, uid, t.ID)
+}
+
+// Remove Task Dependency godoc
+// @Summary Hapus dependency task
+// @Tags Tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param depends_on_id path string true "ID task yang memblokir"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/tasks/{id}/dependencies/{depends_on_id} [delete]
+func (h *Handlers) RemoveTaskDependency(c *gin.Context) {
+	uid := c.GetString("user_id")
+	t, err := h.TaskRepo.GetByID(c.Request.Context(), uid, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	if err := h.TaskRepo.RemoveDependency(c.Request.Context(), t.ID, c.Param("depends_on_id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	h.respondTask(c, uid, t.ID)
+}
+
+// Project Dependency Graph godoc
+// @Summary Graf dependency task dalam project
+// @Description nodes berisi task project, edges berisi dependency (task_id diblokir oleh depends_on_id) yang kedua ujungnya ada di project.
+// @Tags Projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/projects/{id}/dependencies [get]
+func (h *Handlers) ProjectDependencyGraph(c *gin.Context) {
+	p, ok := h.visibleProject(c, c.GetString("user_id"), c.Param("id"))
+	if !ok {
+		return
+	}
+	graph, err := h.TaskRepo.DependencyGraph(c.Request.Context(), p.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": graph})
+}
+
+// respondOpenBlockers menulis 409 dan mengembalikan false bila task t akan
+// dipindah ke Done padahal masih ada task pemblokir yang belum selesai. Pemblokir
+// yang tidak terlihat oleh userID hanya dilaporkan jumlahnya.
+func (h *Handlers) respondOpenBlockers(c *gin.Context, userID string, t *postgres.Task, to postgres.TaskStatus) bool {
+	if to != postgres.TaskStatusDone || t.Status == postgres.TaskStatusDone {
+		return true
+	}
+	blockers, hidden, err := h.TaskRepo.OpenBlockers(c.Request.Context(), t.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return false
+	}
+	if len(blockers) > 0 || hidden > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"response_code":   http.StatusConflict,
+			"error":           "task masih diblokir oleh task yang belum selesai",
+			"blocked_by":      blockers,
+			"hidden_blockers": hidden,
+		})
+		return false
+	}
+	return true
+}
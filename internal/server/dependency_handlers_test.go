@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"backend-work-mate/internal/storage/postgres"
+)
+
+func dependencyHandlers() (*Handlers, *fakeTasks) {
+	tasks := &fakeTasks{
+		byID: map[string]*postgres.Task{
+			"t1":   {ID: "t1", UserID: "u1", Title: "Rilis", Status: postgres.TaskStatusInProgress},
+			"mine": {ID: "mine", UserID: "u1", Title: "Migrasi", Status: postgres.TaskStatusTodo},
+			"hid":  {ID: "hid", UserID: "u2", Title: "Rahasia", Status: postgres.TaskStatusInProgress},
+			"done": {ID: "done", UserID: "u1", Title: "Desain", Status: postgres.TaskStatusDone},
+		},
+		blockers: map[string][]string{},
+	}
+	return &Handlers{TaskRepo: tasks}, tasks
+}
+
+func transition(t *testing.T, h *Handlers, status string) (int, map[string]any) {
+	t.Helper()
+	return serve(t, http.MethodPost, "/tasks/:id/transition", "/tasks/t1/transition", "u1",
+		`{"status":"`+status+`"}`, h.TransitionTask)
+}
+
+func TestTransitionToDoneBlockedByOpenDependency(t *testing.T) {
+	h, tasks := dependencyHandlers()
+	tasks.blockers["t1"] = []string{"mine", "done"}
+
+	code, body := transition(t, h, "Done")
+	if code != http.StatusConflict {
+		t.Fatalf("code = %d, ingin 409: %v", code, body)
+	}
+	blockers := body["blocked_by"].([]any)
+	if len(blockers) != 1 || blockers[0].(map[string]any)["id"] != "mine" {
+		t.Fatalf("blocked_by = %v, ingin hanya task mine", blockers)
+	}
+	if tasks.byID["t1"].Status != postgres.TaskStatusInProgress {
+		t.Fatal("status berubah meski masih diblokir")
+	}
+
+	// status lain selain Done tetap boleh
+	if code, body := transition(t, h, "Blocked"); code != http.StatusOK {
+		t.Fatalf("ke Blocked: code = %d: %v", code, body)
+	}
+}
+
+func TestTransitionToDoneHidesInvisibleBlockers(t *testing.T) {
+	h, tasks := dependencyHandlers()
+	tasks.blockers["t1"] = []string{"hid"}
+
+	code, body := transition(t, h, "Done")
+	if code != http.StatusConflict {
+		t.Fatalf("code = %d, ingin 409: %v", code, body)
+	}
+	if got := body["blocked_by"].([]any); len(got) != 0 {
+		t.Fatalf("blocked_by membocorkan task tersembunyi: %v", got)
+	}
+	if body["hidden_blockers"] != float64(1) {
+		t.Fatalf("hidden_blockers = %v, ingin 1", body["hidden_blockers"])
+	}
+}
+
+func TestTransitionToDoneWhenBlockersClosed(t *testing.T) {
+	h, tasks := dependencyHandlers()
+	tasks.blockers["t1"] = []string{"done"}
+
+	if code, body := transition(t, h, "Done"); code != http.StatusOK {
+		t.Fatalf("code = %d, ingin 200: %v", code, body)
+	}
+	if tasks.byID["t1"].Status != postgres.TaskStatusDone {
+		t.Fatal("status tidak berubah ke Done")
+	}
+}
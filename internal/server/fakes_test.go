@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Fake repository in-memory untuk unit test handler. Interface di-embed agar
+// method yang tidak dipakai test cukup panic bila terpanggil.
+
+// fakeTasks menyimpan task per ID; visible (bila diisi) membatasi task yang
+// terlihat oleh tiap user, selain task yang dibuatnya sendiri.
+type fakeTasks struct {
+	postgres.TaskRepository
+	byID     map[string]*postgres.Task
+	visible  map[string][]string
+	blockers map[string][]string
+}
+
+func (f *fakeTasks) canSee(userID string, t *postgres.Task) bool {
+	if t.UserID == userID {
+		return true
+	}
+	for _, id := range f.visible[userID] {
+		if id == t.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeTasks) GetByID(_ context.Context, userID, id string) (*postgres.Task, error) {
+	t := f.byID[id]
+	if t == nil || !f.canSee(userID, t) {
+		return nil, pgx.ErrNoRows
+	}
+	cp := *t
+	return &cp, nil
+}
+
+func (f *fakeTasks) Update(_ context.Context, t *postgres.Task) error {
+	cp := *t
+	f.byID[t.ID] = &cp
+	return nil
+}
+
+// CheckParent meniru query leluhur/turunan di Postgres atas ParentID in-memory.
+func (f *fakeTasks) CheckParent(_ context.Context, id, parentID string) error {
+	depth := 0
+	for cur := f.byID[parentID]; cur != nil && depth <= postgres.MaxTaskDepth; depth++ {
+		if cur.ID == id {
+			return postgres.ErrTaskCycle
+		}
+		if cur.ParentID == nil {
+			cur = nil
+		} else {
+			cur = f.byID[*cur.ParentID]
+		}
+	}
+	if depth+f.height(id) > postgres.MaxTaskDepth {
+		return postgres.ErrTaskTooDeep
+	}
+	return nil
+}
+
+// height adalah tinggi subtree task id (1 bila tanpa subtask atau task baru).
+func (f *fakeTasks) height(id string) int {
+	h := 1
+	for _, t := range f.byID {
+		if id != "" && t.ParentID != nil && *t.ParentID == id {
+			h = max(h, f.height(t.ID)+1)
+		}
+	}
+	return h
+}
+
+func (f *fakeTasks) OpenBlockers(_ context.Context, taskID, viewerID string) ([]postgres.TaskRef, int, error) {
+	refs := []postgres.TaskRef{}
+	hidden := 0
+	for _, id := range f.blockers[taskID] {
+		b := f.byID[id]
+		if b.Status.Closed() {
+			continue
+		}
+		if !f.canSee(viewerID, b) {
+			hidden++
+			continue
+		}
+		refs = append(refs, postgres.TaskRef{ID: b.ID, Title: b.Title, Status: b.Status})
+	}
+	return refs, hidden, nil
+}
+
+func (f *fakeTasks) Transition(_ context.Context, _, id string, from, to postgres.TaskStatus) (*postgres.Task, error) {
+	t := f.byID[id]
+	if t == nil || t.Status != from {
+		return nil, pgx.ErrNoRows
+	}
+	t.Status = to
+	cp := *t
+	return &cp, nil
+}
+
+type fakeUsers struct {
+	postgres.UserRepository
+	byID map[string]*postgres.User
+}
+
+func (f *fakeUsers) GetByID(_ context.Context, id string) (*postgres.User, error) {
+	return f.byID[id], nil
+}
+
+type fakePATs struct {
+	postgres.PersonalAccessTokenRepository
+	byHash map[string]*postgres.PersonalAccessToken
+}
+
+func (f *fakePATs) Create(_ context.Context, t *postgres.PersonalAccessToken) error {
+	t.ID = fmt.Sprintf("pat-%d", len(f.byHash)+1)
+	t.CreatedAt = time.Now()
+	f.byHash[t.TokenHash] = t
+	return nil
+}
+
+func (f *fakePATs) GetByHash(_ context.Context, tokenHash string) (*postgres.PersonalAccessToken, error) {
+	return f.byHash[tokenHash], nil
+}
+
+func (f *fakePATs) Touch(context.Context, string) error {
+	return nil
+}
+
+// byID mencari PAT berdasarkan ID agar test bisa mencabut atau mengubah masa berlakunya.
+func (f *fakePATs) byID(id string) *postgres.PersonalAccessToken {
+	for _, t := range f.byHash {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// serve menjalankan handler sebagai userID, seperti setelah authMiddleware.
+func serve(t *testing.T, method, route, path, userID, body string, handler gin.HandlerFunc) (int, map[string]any) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Handle(method, route, func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler(c)
+	})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	var out map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("respons bukan JSON: %s", w.Body.String())
+	}
+	return w.Code, out
+}
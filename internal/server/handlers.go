@@ -1,19 +1,30 @@
 package server
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"backend-work-mate/internal/auth"
 	"backend-work-mate/internal/storage/postgres"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type Handlers struct {
-	AuthSvc   *auth.Service
-	TaskRepo  postgres.TaskRepository
-	JWTSecret []byte
+	AuthSvc       *auth.Service
+	TaskRepo      postgres.TaskRepository
+	LabelRepo     postgres.LabelRepository
+	ProjectRepo   postgres.ProjectRepository
+	ChecklistRepo postgres.ChecklistRepository
+	UserRepo      postgres.UserRepository
+	JWTSecret     []byte
 }
 
 // Healthz godoc
@@ -26,6 +37,18 @@ func (h *Handlers) Healthz(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "status": "ok"})
 }
 
+// JWKS godoc
+// @Summary Public key verifikasi access token (JSON Web Key Set)
+// @Tags Misc
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /.well-known/jwks.json [get]
+func (h *Handlers) JWKS(c *gin.Context) {
+	// format standar RFC 7517 tanpa response_code agar bisa dibaca library JWT
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": h.AuthSvc.JWKS()})
+}
+
 // Register godoc
 // @Summary Register user baru
 // @Tags Auth
@@ -43,6 +66,10 @@ func (h *Handlers) Register(c *gin.Context) {
 	}
 	user, err := h.AuthSvc.Register(c.Request.Context(), in)
 	if err != nil {
+		if errors.Is(err, auth.ErrRegistrationClosed) {
+			c.JSON(http.StatusForbidden, gin.H{"response_code": http.StatusForbidden, "error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
 		return
 	}
@@ -66,6 +93,8 @@ func (h *Handlers) Register(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
 // @Router /api/login [post]
 func (h *Handlers) Login(c *gin.Context) {
 	var in auth.LoginInput
@@ -73,26 +102,242 @@ func (h *Handlers) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
 		return
 	}
-	token, err := h.AuthSvc.Login(c.Request.Context(), in)
+	token, err := h.AuthSvc.Login(c.Request.Context(), in, clientInfo(c))
+	if err != nil {
+		if respondLocked(c, err) {
+			return
+		}
+		if errors.Is(err, auth.ErrEmailNotVerified) {
+			c.JSON(http.StatusForbidden, gin.H{"response_code": http.StatusForbidden, "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"response_code": http.StatusUnauthorized, "error": err.Error()})
+		return
+	}
+	if token.MFARequired {
+		c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "mfa_required": true, "mfa_token": token.MFAToken})
+		return
+	}
+	c.JSON(http.StatusOK, tokenResponse(token))
+}
+
+// Refresh Token godoc
+// @Summary Tukar refresh token dengan token baru
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body auth.RefreshInput true "Refresh payload"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/token/refresh [post]
+func (h *Handlers) RefreshToken(c *gin.Context) {
+	var in auth.RefreshInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	token, err := h.AuthSvc.Refresh(c.Request.Context(), in.RefreshToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"response_code": http.StatusUnauthorized, "error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "token": token.Token})
+	c.JSON(http.StatusOK, tokenResponse(token))
+}
+
+// Forgot Password godoc
+// @Summary Kirim email reset password
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body auth.ForgotPasswordInput true "Email akun"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/password/forgot [post]
+func (h *Handlers) ForgotPassword(c *gin.Context) {
+	var in auth.ForgotPasswordInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	if err := h.AuthSvc.ForgotPassword(c.Request.Context(), in.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "jika email terdaftar, link reset password sudah dikirim"})
+}
+
+// Reset Password godoc
+// @Summary Reset password memakai token dari email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body auth.ResetPasswordInput true "Token dan password baru"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/password/reset [post]
+func (h *Handlers) ResetPassword(c *gin.Context) {
+	var in auth.ResetPasswordInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	if err := h.AuthSvc.ResetPassword(c.Request.Context(), in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "password berhasil direset"})
+}
+
+// Password Policy godoc
+// @Summary Kebijakan password yang berlaku untuk password baru
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/password/policy [get]
+func (h *Handlers) PasswordPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": h.AuthSvc.PasswordPolicy()})
+}
+
+// Verify Email godoc
+// @Summary Verifikasi email memakai token dari email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body auth.VerifyEmailInput true "Token verifikasi"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/verify-email [post]
+func (h *Handlers) VerifyEmail(c *gin.Context) {
+	var in auth.VerifyEmailInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	if err := h.AuthSvc.VerifyEmail(c.Request.Context(), in.Token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "email berhasil diverifikasi"})
+}
+
+// Resend Verification godoc
+// @Summary Kirim ulang email verifikasi
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body auth.ResendVerificationInput true "Email akun"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/verify-email/resend [post]
+func (h *Handlers) ResendVerification(c *gin.Context) {
+	var in auth.ResendVerificationInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	if err := h.AuthSvc.ResendVerification(c.Request.Context(), in.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "jika email terdaftar dan belum diverifikasi, email verifikasi sudah dikirim"})
+}
+
+// Logout godoc
+// @Summary Logout (cabut access token saat ini dan refresh token-nya)
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body auth.LogoutInput false "Refresh token yang ikut dicabut"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/logout [post]
+func (h *Handlers) Logout(c *gin.Context) {
+	var in auth.LogoutInput
+	// body opsional: request tanpa body tetap valid
+	if err := c.ShouldBindJSON(&in); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	if err := h.AuthSvc.Logout(c.Request.Context(), currentClaims(c), in.RefreshToken); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "logged out"})
+}
+
+// Logout All godoc
+// @Summary Logout dari semua perangkat
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/logout/all [post]
+func (h *Handlers) LogoutAll(c *gin.Context) {
+	if err := h.AuthSvc.LogoutAll(c.Request.Context(), c.GetString("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "logged out from all devices"})
+}
+
+// clientInfo mengambil IP dan user agent untuk lockout login dan session. ClientIP
+// hanya membaca X-Forwarded-For dari proxy di TRUSTED_PROXIES, sehingga IP yang
+// tersimpan di session tidak bisa dipalsukan lewat header.
+func clientInfo(c *gin.Context) auth.ClientInfo {
+	return auth.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// respondLocked menulis 429 dengan header Retry-After bila err adalah *auth.LockedError.
+func respondLocked(c *gin.Context, err error) bool {
+	var locked *auth.LockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	secs := int(math.Ceil(locked.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(secs))
+	c.JSON(http.StatusTooManyRequests, gin.H{"response_code": http.StatusTooManyRequests, "error": err.Error(), "retry_after": secs})
+	return true
+}
+
+func tokenResponse(t *auth.AuthToken) gin.H {
+	return gin.H{
+		"response_code": http.StatusOK,
+		"token":         t.Token,
+		"refresh_token": t.RefreshToken,
+		"token_type":    t.TokenType,
+		"expires_in":    t.ExpiresIn,
+	}
 }
 
 type CreateTaskInput struct {
-	Title       string  `json:"title" binding:"required"`
-	Description *string `json:"description"`
-	Status      *string `json:"status"`
-	DueDate     *string `json:"due_date"`
+	Title       string                 `json:"title" binding:"required"`
+	Description *string                `json:"description"`
+	Status      *postgres.TaskStatus   `json:"status"`
+	Priority    *postgres.TaskPriority `json:"priority"`
+	DueDate     *string                `json:"due_date"`
+	ProjectID   *string                `json:"project_id"`
+	ParentID    *string                `json:"parent_id"`
 }
 
 type UpdateTaskInput struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Status      *string `json:"status"`
-	DueDate     *string `json:"due_date"`
+	Title       *string                `json:"title"`
+	Description *string                `json:"description"`
+	Status      *postgres.TaskStatus   `json:"status"`
+	Priority    *postgres.TaskPriority `json:"priority"`
+	DueDate     *string                `json:"due_date"`
+	// ProjectID kosong ("") mengeluarkan task dari project. Hanya pembuat task yang
+	// boleh mengubahnya, dan tidak bisa bila project asalnya sudah diarsipkan.
+	ProjectID *string `json:"project_id"`
+	// ParentID kosong ("") menjadikan subtask task utama lagi. Hanya pembuat task yang
+	// boleh mengubahnya, dan parent harus dibuat oleh user yang sama.
+	ParentID *string `json:"parent_id"`
+}
+
+type TransitionTaskInput struct {
+	Status postgres.TaskStatus `json:"status" binding:"required"`
 }
 
 // Create Task godoc
@@ -116,16 +361,39 @@ func (h *Handlers) CreateTask(c *gin.Context) {
 	if in.Description != nil {
 		t.Description = in.Description
 	}
+	t.Status = postgres.TaskStatusTodo
 	if in.Status != nil {
+		if !in.Status.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "invalid status", "allowed": postgres.TaskStatuses})
+			return
+		}
 		t.Status = *in.Status
-	} else {
-		t.Status = "Todo"
+	}
+	t.Priority = postgres.TaskPriorityMedium
+	if in.Priority != nil {
+		if !in.Priority.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "invalid priority", "allowed": postgres.TaskPriorities})
+			return
+		}
+		t.Priority = *in.Priority
 	}
 	if in.DueDate != nil && *in.DueDate != "" {
 		if dt, err := time.Parse(time.RFC3339, *in.DueDate); err == nil {
 			t.DueDate = &dt
 		}
 	}
+	if in.ProjectID != nil && *in.ProjectID != "" {
+		if !h.respondTaskProject(c, uid, *in.ProjectID) {
+			return
+		}
+		t.ProjectID = in.ProjectID
+	}
+	if in.ParentID != nil && *in.ParentID != "" {
+		if !h.respondTaskParent(c, uid, "", *in.ParentID) {
+			return
+		}
+		t.ParentID = in.ParentID
+	}
 	if err := h.TaskRepo.Create(c.Request.Context(), t); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
 		return
@@ -134,20 +402,87 @@ func (h *Handlers) CreateTask(c *gin.Context) {
 }
 
 // List Tasks godoc
-// @Summary List task milik user
+// @Summary List task yang terlihat oleh user (dibuat, di-assign, atau dalam project-nya)
 // @Tags Tasks
 // @Security BearerAuth
 // @Produce json
+// @Param view query string false "assigned (di-assign ke saya), created (dibuat saya), kosong = semua"
+// @Param project_id query string false "Hanya task dalam project ini"
+// @Param status query []string false "Filter status (boleh berulang atau dipisah koma)" collectionFormat(multi)
+// @Param due_from query string false "Due date mulai (RFC3339 atau YYYY-MM-DD)"
+// @Param due_to query string false "Due date sampai (RFC3339 atau YYYY-MM-DD)"
+// @Param overdue query bool false "Hanya task yang lewat due date dan belum Done/Cancelled"
+// @Param created_from query string false "Dibuat mulai"
+// @Param created_to query string false "Dibuat sampai"
+// @Param updated_from query string false "Diubah mulai"
+// @Param updated_to query string false "Diubah sampai"
+// @Param priority query []string false "Filter priority: Low, Medium, High, Urgent" collectionFormat(multi)
+// @Param label query []string false "Filter label ID (minimal salah satu)" collectionFormat(multi)
+// @Param q query string false "Cari di title dan description"
+// @Param sort query string false "created_at (default), updated_at, due_date, title, priority, position"
+// @Param order query string false "asc atau desc"
+// @Param limit query int false "Jumlah data per halaman (default 20, maks 100)"
+// @Param cursor query string false "next_cursor/prev_cursor dari response sebelumnya"
+// @Param include_total query bool false "Sertakan total data yang cocok dengan filter"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Router /api/tasks [get]
 func (h *Handlers) ListTasks(c *gin.Context) {
 	uid := c.GetString("user_id")
-	items, err := h.TaskRepo.ListByUser(c.Request.Context(), uid, 50, 0)
+	f, err := parseTaskFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	items, info, err := h.TaskRepo.ListByUser(c.Request.Context(), uid, f, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items, "pagination": info})
+}
+
+// Search Tasks godoc
+// @Summary Full-text search task yang terlihat oleh user (diurutkan berdasarkan relevansi)
+// @Tags Tasks
+// @Security BearerAuth
+// @Produce json
+// @Param q query string true "Kata kunci (sintaks websearch_to_tsquery)"
+// @Param view query string false "assigned, created, atau kosong = semua"
+// @Param status query []string false "Filter status" collectionFormat(multi)
+// @Param limit query int false "Jumlah data per halaman (default 20, maks 100)"
+// @Param cursor query string false "next_cursor/prev_cursor dari response sebelumnya"
+// @Param include_total query bool false "Sertakan total hasil"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/tasks/search [get]
+func (h *Handlers) SearchTasks(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "q wajib diisi"})
+		return
+	}
+	f, err := parseTaskFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	items, info, err := h.TaskRepo.Search(c.Request.Context(), c.GetString("user_id"), q, f, page)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items})
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items, "pagination": info})
 }
 
 // Get Task by ID godoc
@@ -180,7 +515,9 @@ func (h *Handlers) GetTask(c *gin.Context) {
 // @Param request body UpdateTaskInput true "Task update"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Router /api/tasks/{id} [put]
 func (h *Handlers) UpdateTask(c *gin.Context) {
 	var in UpdateTaskInput
@@ -190,10 +527,19 @@ func (h *Handlers) UpdateTask(c *gin.Context) {
 	}
 	uid := c.GetString("user_id")
 	id := c.Param("id")
-	t, err := h.TaskRepo.GetByID(c.Request.Context(), uid, id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
-		return
+	var t *postgres.Task
+	if in.ProjectID != nil || in.ParentID != nil {
+		// hanya pembuat task yang boleh memindahkan task ke project atau parent lain
+		var ok bool
+		if t, ok = h.ownedTask(c, uid, id); !ok {
+			return
+		}
+	} else {
+		var err error
+		if t, err = h.TaskRepo.GetByID(c.Request.Context(), uid, id); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+			return
+		}
 	}
 	if in.Title != nil {
 		t.Title = *in.Title
@@ -201,9 +547,19 @@ func (h *Handlers) UpdateTask(c *gin.Context) {
 	if in.Description != nil {
 		t.Description = in.Description
 	}
-	if in.Status != nil {
+	if in.Status != nil && *in.Status != t.Status {
+		if !respondTransition(c, t.Status, *in.Status) || !h.respondOpenBlockers(c, uid, t, *in.Status) {
+			return
+		}
 		t.Status = *in.Status
 	}
+	if in.Priority != nil {
+		if !in.Priority.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "invalid priority", "allowed": postgres.TaskPriorities})
+			return
+		}
+		t.Priority = *in.Priority
+	}
 	if in.DueDate != nil {
 		if *in.DueDate == "" {
 			t.DueDate = nil
@@ -211,6 +567,29 @@ func (h *Handlers) UpdateTask(c *gin.Context) {
 			t.DueDate = &dt
 		}
 	}
+	if in.ProjectID != nil && (t.ProjectID == nil || *t.ProjectID != *in.ProjectID) {
+		if t.ProjectID != nil && !h.respondProjectNotArchived(c, *t.ProjectID) {
+			return
+		}
+		if *in.ProjectID == "" {
+			t.ProjectID = nil
+		} else {
+			if !h.respondTaskProject(c, uid, *in.ProjectID) {
+				return
+			}
+			t.ProjectID = in.ProjectID
+		}
+	}
+	if in.ParentID != nil {
+		if *in.ParentID == "" {
+			t.ParentID = nil
+		} else if t.ParentID == nil || *t.ParentID != *in.ParentID {
+			if !h.respondTaskParent(c, uid, t.ID, *in.ParentID) {
+				return
+			}
+			t.ParentID = in.ParentID
+		}
+	}
 	if err := h.TaskRepo.Update(c.Request.Context(), t); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
 		return
@@ -218,6 +597,64 @@ func (h *Handlers) UpdateTask(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": t})
 }
 
+// Transition Task godoc
+// @Summary Pindahkan status task sesuai alur kerja
+// @Description Todo -> In Progress/Blocked/Done/Cancelled, In Progress -> Todo/Blocked/Done/Cancelled, Blocked -> Todo/In Progress/Cancelled, Done -> Todo/In Progress, Cancelled -> Todo
+// @Tags Tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body TransitionTaskInput true "Status tujuan"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/tasks/{id}/transition [post]
+func (h *Handlers) TransitionTask(c *gin.Context) {
+	var in TransitionTaskInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	uid := c.GetString("user_id")
+	t, err := h.TaskRepo.GetByID(c.Request.Context(), uid, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	if !respondTransition(c, t.Status, in.Status) || !h.respondOpenBlockers(c, uid, t, in.Status) {
+		return
+	}
+	updated, err := h.TaskRepo.Transition(c.Request.Context(), t.UserID, t.ID, t.Status, in.Status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusConflict, gin.H{"response_code": http.StatusConflict, "error": "status task sudah berubah, muat ulang task"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": updated})
+}
+
+// respondTransition menulis 400 dan mengembalikan false bila perpindahan status tidak diizinkan.
+func respondTransition(c *gin.Context, from, to postgres.TaskStatus) bool {
+	if !to.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "invalid status", "allowed": postgres.TaskStatuses})
+		return false
+	}
+	if !from.CanTransitionTo(to) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"response_code": http.StatusBadRequest,
+			"error":         fmt.Sprintf("status tidak bisa diubah dari %s ke %s", from, to),
+			"allowed":       from.Transitions(),
+		})
+		return false
+	}
+	return true
+}
+
 // Delete Task godoc
 // @Summary Hapus task
 // @Tags Tasks
@@ -225,11 +662,15 @@ func (h *Handlers) UpdateTask(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Task ID"
 // @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Router /api/tasks/{id} [delete]
 func (h *Handlers) DeleteTask(c *gin.Context) {
 	uid := c.GetString("user_id")
-	id := c.Param("id")
-	if err := h.TaskRepo.Delete(c.Request.Context(), uid, id); err != nil {
+	t, ok := h.ownedTask(c, uid, c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := h.TaskRepo.Delete(c.Request.Context(), uid, t.ID); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
 		return
 	}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"backend-work-mate/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Accept Invitation godoc
+// @Summary Terima undangan: buat akun dengan nama dan password, lalu login
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body auth.AcceptInvitationInput true "Token undangan, nama, dan password"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/invitations/accept [post]
+func (h *Handlers) AcceptInvitation(c *gin.Context) {
+	var in auth.AcceptInvitationInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	token, err := h.AuthSvc.AcceptInvitation(c.Request.Context(), in, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	resp := tokenResponse(token)
+	resp["response_code"] = http.StatusCreated
+	c.JSON(http.StatusCreated, resp)
+}
+
+// Admin Create Invitation godoc
+// @Summary Undang user baru lewat email (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body auth.CreateInvitationInput true "Email, role, dan department"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/admin/invitations [post]
+func (h *Handlers) AdminCreateInvitation(c *gin.Context) {
+	var in auth.CreateInvitationInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	inv, err := h.AuthSvc.CreateInvitation(c.Request.Context(), c.GetString("user_id"), in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"response_code": http.StatusCreated, "data": inv})
+}
+
+// Admin List Invitations godoc
+// @Summary List undangan (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param pending query bool false "Hanya undangan yang masih berlaku"
+// @Param limit query int false "Jumlah data (maks 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/admin/invitations [get]
+func (h *Handlers) AdminListInvitations(c *gin.Context) {
+	pendingOnly := c.Query("pending") == "true"
+	items, total, err := h.AuthSvc.ListInvitations(c.Request.Context(), pendingOnly, queryInt(c, "limit", 20), queryInt(c, "offset", 0))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items, "total": total})
+}
+
+// Admin Revoke Invitation godoc
+// @Summary Batalkan undangan yang belum diterima (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/admin/invitations/{id} [delete]
+func (h *Handlers) AdminRevokeInvitation(c *gin.Context) {
+	if err := h.AuthSvc.RevokeInvitation(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "revoked"})
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const defaultLabelColor = "#6B7280"
+
+type CreateLabelInput struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color" binding:"omitempty,hexcolor"`
+}
+
+type UpdateLabelInput struct {
+	Name  *string `json:"name" binding:"omitempty,min=1,max=50"`
+	Color *string `json:"color" binding:"omitempty,hexcolor"`
+}
+
+type AttachLabelInput struct {
+	LabelID string `json:"label_id" binding:"required"`
+}
+
+// List Labels godoc
+// @Summary List label milik user
+// @Tags Labels
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/labels [get]
+func (h *Handlers) ListLabels(c *gin.Context) {
+	items, err := h.LabelRepo.ListByUser(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items})
+}
+
+// Create Label godoc
+// @Summary Buat label
+// @Tags Labels
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateLabelInput true "Nama dan warna (hex, mis. #EF4444)"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/labels [post]
+func (h *Handlers) CreateLabel(c *gin.Context) {
+	var in CreateLabelInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	l := &postgres.Label{UserID: c.GetString("user_id"), Name: in.Name, Color: in.Color}
+	if l.Color == "" {
+		l.Color = defaultLabelColor
+	}
+	if err := h.LabelRepo.Create(c.Request.Context(), l); err != nil {
+		respondLabelError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"response_code": http.StatusCreated, "data": l})
+}
+
+// Update Label godoc
+// @Summary Ubah nama/warna label
+// @Tags Labels
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Label ID"
+// @Param request body UpdateLabelInput true "Field yang diubah"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/labels/{id} [patch]
+func (h *Handlers) UpdateLabel(c *gin.Context) {
+	var in UpdateLabelInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	l, err := h.LabelRepo.GetByID(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil || l == nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	if in.Name != nil {
+		l.Name = *in.Name
+	}
+	if in.Color != nil {
+		l.Color = *in.Color
+	}
+	if err := h.LabelRepo.Update(c.Request.Context(), l); err != nil {
+		respondLabelError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": l})
+}
+
+// Delete Label godoc
+// @Summary Hapus label (otomatis dilepas dari semua task)
+// @Tags Labels
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Label ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/labels/{id} [delete]
+func (h *Handlers) DeleteLabel(c *gin.Context) {
+	if err := h.LabelRepo.Delete(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		respondLabelError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "deleted"})
+}
+
+// Attach Task Label godoc
+// @Summary Pasang label ke task
+// @Tags Labels
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body AttachLabelInput true "Label ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/tasks/{id}/labels [post]
+func (h *Handlers) AttachTaskLabel(c *gin.Context) {
+	var in AttachLabelInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	uid := c.GetString("user_id")
+	t, ok := h.ownedTask(c, uid, c.Param("id"))
+	if !ok {
+		return
+	}
+	l, err := h.LabelRepo.GetByID(c.Request.Context(), uid, in.LabelID)
+	if err != nil || l == nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "label not found"})
+		return
+	}
+	if err := h.LabelRepo.Attach(c.Request.Context(), t.ID, l.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	h.respondTask(c, uid, t.ID)
+}
+
+// Detach Task Label godoc
+// @Summary Lepas label dari task
+// @Tags Labels
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param label_id path string true "Label ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/tasks/{id}/labels/{label_id} [delete]
+func (h *Handlers) DetachTaskLabel(c *gin.Context) {
+	uid := c.GetString("user_id")
+	t, ok := h.ownedTask(c, uid, c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := h.LabelRepo.Detach(c.Request.Context(), t.ID, c.Param("label_id")); err != nil {
+		respondLabelError(c, err)
+		return
+	}
+	h.respondTask(c, uid, t.ID)
+}
+
+// respondTask mengirim task terbaru (beserta label dan assignee) setelah perubahan relasi.
+func (h *Handlers) respondTask(c *gin.Context, userID, id string) {
+	t, err := h.TaskRepo.GetByID(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": t})
+}
+
+func respondLabelError(c *gin.Context, err error) {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+	case errors.As(err, &pgErr) && pgErr.Code == "23505":
+		c.JSON(http.StatusConflict, gin.H{"response_code": http.StatusConflict, "error": "label dengan nama tersebut sudah ada"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"net/http"
+
+	"backend-work-mate/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Login MFA godoc
+// @Summary Langkah kedua login dengan kode TOTP atau recovery code
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body auth.MFAVerifyInput true "Token tantangan dan kode"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /api/login/mfa [post]
+func (h *Handlers) LoginMFA(c *gin.Context) {
+	var in auth.MFAVerifyInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	token, err := h.AuthSvc.VerifyMFA(c.Request.Context(), in, clientInfo(c))
+	if err != nil {
+		if respondLocked(c, err) {
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"response_code": http.StatusUnauthorized, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tokenResponse(token))
+}
+
+// MFA Status godoc
+// @Summary Status MFA user yang sedang login
+// @Tags MFA
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/mfa [get]
+func (h *Handlers) MFAStatus(c *gin.Context) {
+	status, err := h.AuthSvc.MFAStatus(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": status})
+}
+
+// MFA Enroll godoc
+// @Summary Mulai pendaftaran TOTP (secret dan URI untuk QR code)
+// @Tags MFA
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/mfa/enroll [post]
+func (h *Handlers) EnrollMFA(c *gin.Context) {
+	enrollment, err := h.AuthSvc.EnrollMFA(c.Request.Context(), currentClaims(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": enrollment})
+}
+
+// MFA Confirm godoc
+// @Summary Aktifkan MFA dengan kode TOTP pertama dan dapatkan recovery code
+// @Tags MFA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body auth.MFACodeInput true "Kode TOTP"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/mfa/confirm [post]
+func (h *Handlers) ConfirmMFA(c *gin.Context) {
+	var in auth.MFACodeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	codes, err := h.AuthSvc.ConfirmMFA(c.Request.Context(), c.GetString("user_id"), in.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "recovery_codes": codes})
+}
+
+// MFA Disable godoc
+// @Summary Nonaktifkan MFA
+// @Tags MFA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body auth.MFACodeInput true "Kode TOTP atau recovery code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/mfa/disable [post]
+func (h *Handlers) DisableMFA(c *gin.Context) {
+	var in auth.MFACodeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	if err := h.AuthSvc.DisableMFA(c.Request.Context(), c.GetString("user_id"), in.Code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "MFA dinonaktifkan"})
+}
+
+// MFA Recovery Codes godoc
+// @Summary Buat ulang recovery code
+// @Tags MFA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body auth.MFACodeInput true "Kode TOTP"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/mfa/recovery-codes [post]
+func (h *Handlers) RegenerateRecoveryCodes(c *gin.Context) {
+	var in auth.MFACodeInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	codes, err := h.AuthSvc.RegenerateRecoveryCodes(c.Request.Context(), c.GetString("user_id"), in.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "recovery_codes": codes})
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"backend-work-mate/internal/auth"
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authMiddleware memvalidasi bearer token dan menyimpan "user_id" serta "claims" di context.
+func authMiddleware(authSvc *auth.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"response_code": http.StatusUnauthorized, "error": "missing bearer token"})
+			return
+		}
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		claims, err := authSvc.Authenticate(c.Request.Context(), tokenString)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"response_code": http.StatusUnauthorized, "error": err.Error()})
+			return
+		}
+		c.Set("user_id", claims.Subject)
+		c.Set("claims", claims)
+		c.Next()
+	}
+}
+
+// currentClaims mengambil claims yang diset oleh authMiddleware.
+func currentClaims(c *gin.Context) *auth.Claims {
+	if v, ok := c.Get("claims"); ok {
+		if claims, ok := v.(*auth.Claims); ok {
+			return claims
+		}
+	}
+	return nil
+}
+
+// RequireRole hanya meneruskan request bila role user termasuk salah satu roles.
+// Harus dipasang setelah authMiddleware.
+func RequireRole(roles ...postgres.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := currentClaims(c)
+		if claims != nil {
+			for _, role := range roles {
+				if claims.Role == role {
+					c.Next()
+					return
+				}
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"response_code": http.StatusForbidden, "error": "forbidden"})
+	}
+}
+
+// RequirePermission hanya meneruskan request bila role user memiliki permission perm.
+// Harus dipasang setelah authMiddleware.
+func RequirePermission(perm auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := currentClaims(c)
+		if claims == nil || !auth.HasPermission(claims.Role, perm) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"response_code": http.StatusForbidden, "error": "forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireMFA menolak sesi yang belum lolos verifikasi MFA.
+// Harus dipasang setelah authMiddleware.
+func RequireMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := currentClaims(c)
+		if claims == nil || !claims.MFA {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"response_code": http.StatusForbidden, "error": "MFA wajib diaktifkan dan diverifikasi untuk akses ini"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession menolak request yang memakai personal access token, untuk
+// endpoint sensitif yang hanya boleh diakses dari sesi login.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := currentClaims(c)
+		if claims == nil || claims.IsPersonalAccessToken() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"response_code": http.StatusForbidden, "error": "endpoint ini tidak bisa diakses dengan personal access token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope memastikan personal access token memiliki scope tertentu.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := currentClaims(c)
+		if claims == nil || !claims.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"response_code": http.StatusForbidden, "error": "insufficient scope: " + scope})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScopeByMethod memakai readScope untuk GET/HEAD dan writeScope untuk method lain.
+func RequireScopeByMethod(readScope, writeScope string) gin.HandlerFunc {
+	read, write := RequireScope(readScope), RequireScope(writeScope)
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			read(c)
+			return
+		}
+		write(c)
+	}
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend-work-mate/internal/auth"
+	"backend-work-mate/internal/config"
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+)
+
+// patRouter memasang middleware seperti di NewRouter pada handler dummy yang
+// selalu 200, dengan Service yang memakai repository in-memory.
+func patRouter(t *testing.T) (*gin.Engine, *auth.Service, *fakePATs) {
+	t.Helper()
+	cfg := &config.Config{JWTSecret: "test-secret"}
+	keys, err := auth.LoadKeySet(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &fakeUsers{byID: map[string]*postgres.User{
+		"u1":    {ID: "u1", Email: "budi@example.com", Role: postgres.RoleEmployee, IsActive: true},
+		"admin": {ID: "admin", Email: "admin@example.com", Role: postgres.RoleAdmin, IsActive: true},
+	}}
+	pats := &fakePATs{byHash: map[string]*postgres.PersonalAccessToken{}}
+	svc := auth.NewService(auth.Store{Users: users, PATs: pats}, nil, keys, cfg)
+
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK}) }
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	authMW := authMiddleware(svc)
+	r.GET("/api/me", authMW, RequireScope(auth.ScopeProfileRead), ok)
+	tasks := r.Group("/api/tasks", authMW, RequireScopeByMethod(auth.ScopeTasksRead, auth.ScopeTasksWrite))
+	tasks.GET("", ok)
+	tasks.POST("", ok)
+	r.GET("/api/admin/users", authMW, RequireSession(), RequireRole(postgres.RoleAdmin), RequirePermission(auth.PermUsersManage), ok)
+	return r, svc, pats
+}
+
+func createPAT(t *testing.T, svc *auth.Service, userID string, scopes ...string) *auth.CreatedPAT {
+	t.Helper()
+	pat, err := svc.CreatePersonalAccessToken(context.Background(), userID, auth.CreatePATInput{Name: "ci", Scopes: scopes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pat
+}
+
+func request(r *gin.Engine, method, path, token string) int {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestPATScopes(t *testing.T) {
+	r, svc, _ := patRouter(t)
+	read := createPAT(t, svc, "u1", auth.ScopeTasksRead).Token
+	write := createPAT(t, svc, "u1", auth.ScopeTasksRead, auth.ScopeTasksWrite).Token
+	profile := createPAT(t, svc, "u1", auth.ScopeProfileRead).Token
+	adminPAT := createPAT(t, svc, "admin", auth.ScopeTasksRead, auth.ScopeTasksWrite, auth.ScopeProfileRead).Token
+
+	tests := []struct {
+		name, method, path, token string
+		want                      int
+	}{
+		{"baca task dengan tasks:read", http.MethodGet, "/api/tasks", read, http.StatusOK},
+		{"tulis task tanpa tasks:write", http.MethodPost, "/api/tasks", read, http.StatusForbidden},
+		{"tulis task dengan tasks:write", http.MethodPost, "/api/tasks", write, http.StatusOK},
+		{"profil tanpa profile:read", http.MethodGet, "/api/me", write, http.StatusForbidden},
+		{"profil dengan profile:read", http.MethodGet, "/api/me", profile, http.StatusOK},
+		{"task dengan scope lain", http.MethodGet, "/api/tasks", profile, http.StatusForbidden},
+		{"endpoint admin dengan PAT admin", http.MethodGet, "/api/admin/users", adminPAT, http.StatusForbidden},
+		{"tanpa token", http.MethodGet, "/api/tasks", "", http.StatusUnauthorized},
+		{"PAT tidak dikenal", http.MethodGet, "/api/tasks", "wmpat_tidak-ada", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := request(r, tt.method, tt.path, tt.token); got != tt.want {
+			t.Errorf("%s: code = %d, ingin %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRevokedOrExpiredPATUnauthorized(t *testing.T) {
+	r, svc, pats := patRouter(t)
+	revoked := createPAT(t, svc, "u1", auth.ScopeTasksRead)
+	expired := createPAT(t, svc, "u1", auth.ScopeTasksRead)
+	for _, pat := range []*auth.CreatedPAT{revoked, expired} {
+		if got := request(r, http.MethodGet, "/api/tasks", pat.Token); got != http.StatusOK {
+			t.Fatalf("PAT aktif: code = %d, ingin 200", got)
+		}
+	}
+
+	now := time.Now()
+	pats.byID(revoked.Info.ID).RevokedAt = &now
+	past := now.Add(-time.Minute)
+	pats.byID(expired.Info.ID).ExpiresAt = &past
+
+	if got := request(r, http.MethodGet, "/api/tasks", revoked.Token); got != http.StatusUnauthorized {
+		t.Errorf("PAT dicabut: code = %d, ingin 401", got)
+	}
+	if got := request(r, http.MethodGet, "/api/tasks", expired.Token); got != http.StatusUnauthorized {
+		t.Errorf("PAT kedaluwarsa: code = %d, ingin 401", got)
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for role, want := range map[postgres.UserRole]int{
+		postgres.RoleAdmin:    http.StatusOK,
+		postgres.RoleEmployee: http.StatusForbidden,
+	} {
+		r := gin.New()
+		r.GET("/", func(c *gin.Context) {
+			c.Set("claims", &auth.Claims{Role: role})
+		}, RequirePermission(auth.PermUsersManage), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		if got := request(r, http.MethodGet, "/", ""); got != want {
+			t.Errorf("role %s: code = %d, ingin %d", role, got, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"backend-work-mate/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDC Login godoc
+// @Summary Mulai login SSO (redirect ke IdP)
+// @Description Default-nya merespons 302 ke IdP; dengan ?redirect=false URL dikembalikan sebagai JSON.
+// @Tags Auth
+// @Produce json
+// @Param redirect query bool false "false untuk menerima authorization_url sebagai JSON"
+// @Success 200 {object} map[string]interface{}
+// @Success 302
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/oidc/login [get]
+func (h *Handlers) OIDCLogin(c *gin.Context) {
+	authURL, err := h.AuthSvc.StartOIDCLogin(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, auth.ErrOIDCDisabled) {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"response_code": http.StatusBadGateway, "error": err.Error()})
+		return
+	}
+	if c.Query("redirect") == "false" {
+		c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "authorization_url": authURL})
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDC Callback godoc
+// @Summary Selesaikan login SSO dan terbitkan token Workmate
+// @Tags Auth
+// @Produce json
+// @Param code query string true "Authorization code dari IdP"
+// @Param state query string true "State dari langkah login"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/oidc/callback [get]
+func (h *Handlers) OIDCCallback(c *gin.Context) {
+	if e := c.Query("error"); e != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": e, "error_description": c.Query("error_description")})
+		return
+	}
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "code dan state wajib diisi"})
+		return
+	}
+	token, err := h.AuthSvc.CompleteOIDCLogin(c.Request.Context(), code, state, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"response_code": http.StatusUnauthorized, "error": err.Error()})
+		return
+	}
+	if token.MFARequired {
+		c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "mfa_required": true, "mfa_token": token.MFAToken})
+		return
+	}
+	c.JSON(http.StatusOK, tokenResponse(token))
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"backend-work-mate/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// List Personal Access Tokens godoc
+// @Summary List personal access token milik user
+// @Tags Tokens
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/tokens [get]
+func (h *Handlers) ListPersonalAccessTokens(c *gin.Context) {
+	items, err := h.AuthSvc.ListPersonalAccessTokens(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items})
+}
+
+// Create Personal Access Token godoc
+// @Summary Buat personal access token (token hanya ditampilkan sekali)
+// @Tags Tokens
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body auth.CreatePATInput true "Nama, scope, dan masa berlaku"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/tokens [post]
+func (h *Handlers) CreatePersonalAccessToken(c *gin.Context) {
+	var in auth.CreatePATInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	created, err := h.AuthSvc.CreatePersonalAccessToken(c.Request.Context(), c.GetString("user_id"), in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"response_code": http.StatusCreated, "token": created.Token, "data": created.Info})
+}
+
+// Revoke Personal Access Token godoc
+// @Summary Cabut personal access token
+// @Tags Tokens
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Token ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/tokens/{id} [delete]
+func (h *Handlers) RevokePersonalAccessToken(c *gin.Context) {
+	if err := h.AuthSvc.RevokePersonalAccessToken(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "revoked"})
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/http"
+
+	"backend-work-mate/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateProfileInput struct {
+	Name       *string `json:"name"`
+	Department *string `json:"department"`
+}
+
+// Get Me godoc
+// @Summary Profil user yang sedang login
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/me [get]
+func (h *Handlers) GetMe(c *gin.Context) {
+	user, err := h.UserRepo.GetByID(c.Request.Context(), c.GetString("user_id"))
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": user})
+}
+
+// Update Me godoc
+// @Summary Update nama atau department user yang sedang login
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body UpdateProfileInput true "Profile update"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/me [patch]
+func (h *Handlers) UpdateMe(c *gin.Context) {
+	var in UpdateProfileInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	user, err := h.UserRepo.GetByID(c.Request.Context(), c.GetString("user_id"))
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return
+	}
+	if in.Name != nil {
+		if *in.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "name tidak boleh kosong"})
+			return
+		}
+		user.Name = *in.Name
+	}
+	if in.Department != nil {
+		if *in.Department == "" {
+			user.Department = nil
+		} else {
+			user.Department = in.Department
+		}
+	}
+	if err := h.UserRepo.Update(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": user})
+}
+
+// Change Password godoc
+// @Summary Ganti password (sesi lain otomatis dicabut)
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body auth.ChangePasswordInput true "Password lama dan baru"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/me/password [post]
+func (h *Handlers) ChangePassword(c *gin.Context) {
+	var in auth.ChangePasswordInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	token, err := h.AuthSvc.ChangePassword(c.Request.Context(), currentClaims(c), in, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tokenResponse(token))
+}
@@ -0,0 +1,316 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type CreateProjectInput struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description *string `json:"description"`
+}
+
+type UpdateProjectInput struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description"`
+	Archived    *bool   `json:"archived"`
+}
+
+type AddProjectMemberInput struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// List Projects godoc
+// @Summary List project milik user atau tempat user menjadi anggota
+// @Tags Projects
+// @Security BearerAuth
+// @Produce json
+// @Param include_archived query bool false "Sertakan project yang diarsipkan"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/projects [get]
+func (h *Handlers) ListProjects(c *gin.Context) {
+	includeArchived, _ := strconv.ParseBool(c.Query("include_archived"))
+	items, err := h.ProjectRepo.ListByUser(c.Request.Context(), c.GetString("user_id"), includeArchived)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items})
+}
+
+// Create Project godoc
+// @Summary Buat project
+// @Tags Projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateProjectInput true "Project payload"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/projects [post]
+func (h *Handlers) CreateProject(c *gin.Context) {
+	var in CreateProjectInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	p := &postgres.Project{OwnerID: c.GetString("user_id"), Name: in.Name, Description: in.Description}
+	if err := h.ProjectRepo.Create(c.Request.Context(), p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	p.Members = []postgres.ProjectMember{}
+	c.JSON(http.StatusCreated, gin.H{"response_code": http.StatusCreated, "data": p})
+}
+
+// Get Project godoc
+// @Summary Detail project beserta anggota dan jumlah task
+// @Tags Projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/projects/{id} [get]
+func (h *Handlers) GetProject(c *gin.Context) {
+	p, ok := h.visibleProject(c, c.GetString("user_id"), c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": p})
+}
+
+// Update Project godoc
+// @Summary Ubah atau arsipkan project (owner)
+// @Tags Projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body UpdateProjectInput true "Field yang diubah"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/projects/{id} [patch]
+func (h *Handlers) UpdateProject(c *gin.Context) {
+	var in UpdateProjectInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	p, ok := h.ownedProject(c, c.GetString("user_id"), c.Param("id"))
+	if !ok {
+		return
+	}
+	if in.Name != nil {
+		p.Name = *in.Name
+	}
+	if in.Description != nil {
+		p.Description = in.Description
+	}
+	if in.Archived != nil {
+		p.Archived = *in.Archived
+	}
+	if err := h.ProjectRepo.Update(c.Request.Context(), p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": p})
+}
+
+// Delete Project godoc
+// @Summary Hapus project (owner); task di dalamnya tetap ada tanpa project
+// @Tags Projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/projects/{id} [delete]
+func (h *Handlers) DeleteProject(c *gin.Context) {
+	uid := c.GetString("user_id")
+	p, ok := h.ownedProject(c, uid, c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := h.ProjectRepo.Delete(c.Request.Context(), uid, p.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "deleted"})
+}
+
+// Add Project Member godoc
+// @Summary Tambah anggota project (owner)
+// @Tags Projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body AddProjectMemberInput true "User yang ditambahkan"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/projects/{id}/members [post]
+func (h *Handlers) AddProjectMember(c *gin.Context) {
+	var in AddProjectMemberInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	uid := c.GetString("user_id")
+	p, ok := h.ownedProject(c, uid, c.Param("id"))
+	if !ok {
+		return
+	}
+	u, err := h.UserRepo.GetByID(c.Request.Context(), in.UserID)
+	if err != nil || u == nil || !u.IsActive {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "user not found"})
+		return
+	}
+	if u.ID == p.OwnerID {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "owner sudah termasuk anggota project"})
+		return
+	}
+	if err := h.ProjectRepo.AddMember(c.Request.Context(), p.ID, u.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	if p, ok = h.visibleProject(c, uid, p.ID); ok {
+		c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": p})
+	}
+}
+
+// Remove Project Member godoc
+// @Summary Keluarkan anggota project
+// @Description Owner bisa mengeluarkan siapa saja; anggota hanya bisa keluar sendiri.
+// @Tags Projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param user_id path string true "User ID anggota"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/projects/{id}/members/{user_id} [delete]
+func (h *Handlers) RemoveProjectMember(c *gin.Context) {
+	uid := c.GetString("user_id")
+	target := c.Param("user_id")
+	p, ok := h.visibleProject(c, uid, c.Param("id"))
+	if !ok {
+		return
+	}
+	if p.OwnerID != uid && target != uid {
+		c.JSON(http.StatusForbidden, gin.H{"response_code": http.StatusForbidden, "error": "forbidden"})
+		return
+	}
+	if err := h.ProjectRepo.RemoveMember(c.Request.Context(), p.ID, target); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "removed"})
+}
+
+// List Project Tasks godoc
+// @Summary List task dalam project
+// @Description Menerima filter, sort dan pagination yang sama dengan GET /api/tasks.
+// @Tags Projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param view query string false "assigned, created, atau kosong = semua task project"
+// @Param status query []string false "Filter status" collectionFormat(multi)
+// @Param limit query int false "Jumlah data per halaman (default 20, maks 100)"
+// @Param cursor query string false "next_cursor/prev_cursor dari response sebelumnya"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/projects/{id}/tasks [get]
+func (h *Handlers) ListProjectTasks(c *gin.Context) {
+	uid := c.GetString("user_id")
+	p, ok := h.visibleProject(c, uid, c.Param("id"))
+	if !ok {
+		return
+	}
+	f, err := parseTaskFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	items, info, err := h.TaskRepo.ListByProject(c.Request.Context(), uid, p.ID, f, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items, "pagination": info})
+}
+
+// visibleProject mengambil project yang bisa diakses user (owner atau anggota)
+// dan menulis 404 bila tidak ada.
+func (h *Handlers) visibleProject(c *gin.Context, userID, id string) (*postgres.Project, bool) {
+	p, err := h.ProjectRepo.GetByID(c.Request.Context(), userID, id)
+	if err != nil || p == nil {
+		c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+		return nil, false
+	}
+	return p, true
+}
+
+// ownedProject seperti visibleProject tetapi menulis 403 bila user bukan owner.
+func (h *Handlers) ownedProject(c *gin.Context, userID, id string) (*postgres.Project, bool) {
+	p, ok := h.visibleProject(c, userID, id)
+	if !ok {
+		return nil, false
+	}
+	if p.OwnerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"response_code": http.StatusForbidden, "error": "forbidden"})
+		return nil, false
+	}
+	return p, true
+}
+
+// respondTaskProject memvalidasi project tujuan task: harus bisa diakses user dan
+// belum diarsipkan. Menulis 400 dan mengembalikan false bila tidak valid.
+func (h *Handlers) respondTaskProject(c *gin.Context, userID, projectID string) bool {
+	p, err := h.ProjectRepo.GetByID(c.Request.Context(), userID, projectID)
+	if err != nil || p == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "project not found"})
+		return false
+	}
+	if p.Archived {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "project sudah diarsipkan"})
+		return false
+	}
+	return true
+}
+
+// respondProjectNotArchived menulis 400 dan mengembalikan false bila project asal
+// task sudah diarsipkan; task di project arsip tidak boleh dipindahkan keluar.
+func (h *Handlers) respondProjectNotArchived(c *gin.Context, projectID string) bool {
+	archived, err := h.ProjectRepo.IsArchived(c.Request.Context(), projectID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return false
+	}
+	if archived {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": "project asal task sudah diarsipkan"})
+		return false
+	}
+	return true
+}
@@ -2,11 +2,10 @@ package server
 
 import (
 	"net/http"
-	"strings"
-	"time"
 
 	"backend-work-mate/internal/auth"
 	"backend-work-mate/internal/config"
+	"backend-work-mate/internal/mail"
 	"backend-work-mate/internal/storage/postgres"
 
 	"github.com/gin-gonic/gin"
@@ -15,24 +14,40 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func NewRouter(pool *pgxpool.Pool, cfg *config.Config) http.Handler {
+func NewRouter(pool *pgxpool.Pool, cfg *config.Config, mailer mail.Mailer, keys *auth.KeySet) http.Handler {
 	r := gin.Default()
+	// tanpa proxy tepercaya, X-Forwarded-For diabaikan agar IP client (dipakai
+	// lockout login dan session) tidak bisa dipalsukan; daftar sudah divalidasi config
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic(err)
+	}
 
 	userRepo := postgres.NewUserRepository(pool)
-	authSvc := auth.NewService(userRepo, cfg)
+	authSvc := auth.NewService(auth.Store{
+		Users:         userRepo,
+		RefreshTokens: postgres.NewRefreshTokenRepository(pool),
+		RevokedTokens: postgres.NewRevokedTokenRepository(pool),
+		UserTokens:    postgres.NewUserTokenRepository(pool),
+		MFA:           postgres.NewMFARepository(pool),
+		LoginAttempts: postgres.NewLoginAttemptRepository(pool),
+		PATs:          postgres.NewPersonalAccessTokenRepository(pool),
+		OIDCStates:    postgres.NewOIDCStateRepository(pool),
+		Sessions:      postgres.NewSessionRepository(pool),
+		Invitations:   postgres.NewInvitationRepository(pool),
+	}, mailer, keys, cfg)
+
+	h := &Handlers{
+		AuthSvc:       authSvc,
+		TaskRepo:      postgres.NewTaskRepository(pool),
+		LabelRepo:     postgres.NewLabelRepository(pool),
+		ProjectRepo:   postgres.NewProjectRepository(pool),
+		ChecklistRepo: postgres.NewChecklistRepository(pool),
+		UserRepo:      userRepo,
+		JWTSecret:     []byte(cfg.JWTSecret),
+	}
 
-	// Healthz godoc
-	// @Summary Health check
-	// @Tags Misc
-	// @Produce json
-	// @Success 200 {object} map[string]interface{}
-	// @Router /healthz [get]
-	r.GET("/healthz", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"response_code": http.StatusOK,
-			"status":        "ok",
-		})
-	})
+	r.GET("/healthz", h.Healthz)
+	r.GET("/.well-known/jwks.json", h.JWKS)
 
 	// Swagger UI with explicit doc.json
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(
@@ -40,229 +55,131 @@ func NewRouter(pool *pgxpool.Pool, cfg *config.Config) http.Handler {
 		ginSwagger.URL("/swagger/doc.json"),
 	))
 
-	// Simple JWT auth middleware
-	authMW := func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"response_code": http.StatusUnauthorized, "error": "missing bearer token"})
-			return
-		}
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		userID, err := auth.ParseAndValidateJWT(tokenString, []byte(cfg.JWTSecret))
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"response_code": http.StatusUnauthorized, "error": err.Error()})
-			return
-		}
-		c.Set("user_id", userID)
-		c.Next()
-	}
+	authMW := authMiddleware(authSvc)
+	// sessionMW untuk endpoint yang tidak boleh diakses dengan personal access token
+	sessionMW := RequireSession()
 
 	api := r.Group("/api")
 	{
-		// Register godoc
-		// @Summary Register user baru
-		// @Tags Auth
-		// @Accept json
-		// @Produce json
-		// @Param request body auth.RegisterInput true "Register payload"
-		// @Success 201 {object} map[string]interface{}
-		// @Failure 400 {object} map[string]interface{}
-		// @Router /api/register [post]
-		api.POST("/register", func(c *gin.Context) {
-			var in auth.RegisterInput
-			if err := c.ShouldBindJSON(&in); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
-				return
-			}
-			user, err := authSvc.Register(c.Request.Context(), in)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
-				return
-			}
-			c.JSON(http.StatusCreated, gin.H{"response_code": http.StatusCreated,
-				"id": user.ID, "name": user.Name, "email": user.Email, "role": user.Role, "department": user.Department, "created_at": user.CreatedAt,
-			})
-		})
+		api.POST("/register", h.Register)
+		api.POST("/invitations/accept", h.AcceptInvitation)
+		api.POST("/login", h.Login)
+		api.POST("/login/mfa", h.LoginMFA)
+		api.POST("/token/refresh", h.RefreshToken)
+		api.GET("/oidc/login", h.OIDCLogin)
+		api.GET("/oidc/callback", h.OIDCCallback)
+		api.POST("/password/forgot", h.ForgotPassword)
+		api.POST("/password/reset", h.ResetPassword)
+		api.GET("/password/policy", h.PasswordPolicy)
+		api.POST("/verify-email", h.VerifyEmail)
+		api.POST("/verify-email/resend", h.ResendVerification)
+		api.POST("/logout", authMW, sessionMW, h.Logout)
+		api.POST("/logout/all", authMW, sessionMW, h.LogoutAll)
+	}
 
-		// Login godoc
-		// @Summary Login user
-		// @Tags Auth
-		// @Accept json
-		// @Produce json
-		// @Param request body auth.LoginInput true "Login payload"
-		// @Success 200 {object} map[string]interface{}
-		// @Failure 400 {object} map[string]interface{}
-		// @Failure 401 {object} map[string]interface{}
-		// @Router /api/login [post]
-		api.POST("/login", func(c *gin.Context) {
-			var in auth.LoginInput
-			if err := c.ShouldBindJSON(&in); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
-				return
-			}
-			token, err := authSvc.Login(c.Request.Context(), in)
-			if err != nil {
-				c.JSON(http.StatusUnauthorized, gin.H{"response_code": http.StatusUnauthorized, "error": err.Error()})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "token": token.Token})
-		})
+	// Profile routes (protected)
+	me := r.Group("/api/me", authMW)
+	{
+		me.GET("", RequireScope(auth.ScopeProfileRead), h.GetMe)
+		me.PATCH("", sessionMW, h.UpdateMe)
+		me.POST("/password", sessionMW, h.ChangePassword)
 	}
 
-	// Tasks routes (protected)
-	tasksRepo := postgres.NewTaskRepository(pool)
-	tasks := r.Group("/api/tasks", authMW)
+	// MFA routes (protected)
+	mfa := r.Group("/api/mfa", authMW, sessionMW)
+	{
+		mfa.GET("", h.MFAStatus)
+		mfa.POST("/enroll", h.EnrollMFA)
+		mfa.POST("/confirm", h.ConfirmMFA)
+		mfa.POST("/disable", h.DisableMFA)
+		mfa.POST("/recovery-codes", h.RegenerateRecoveryCodes)
+	}
+
+	// Personal access token routes (protected, session only)
+	tokens := r.Group("/api/tokens", authMW, sessionMW)
+	{
+		tokens.GET("", h.ListPersonalAccessTokens)
+		tokens.POST("", h.CreatePersonalAccessToken)
+		tokens.DELETE("/:id", h.RevokePersonalAccessToken)
+	}
+
+	// Session routes (protected, session only)
+	sessions := r.Group("/api/sessions", authMW, sessionMW)
 	{
-		// Create Task
-		// @Summary Buat task
-		// @Tags Tasks
-		// @Security BearerAuth
-		// @Accept json
-		// @Produce json
-		// @Param request body struct{Title string `json:"title"`; Description *string `json:"description"`; Status *string `json:"status"`; DueDate *string `json:"due_date"`} true "Task payload"
-		// @Success 201 {object} map[string]interface{}
-		// @Failure 400 {object} map[string]interface{}
-		// @Router /api/tasks [post]
-		tasks.POST("", func(c *gin.Context) {
-			var in struct {
-				Title       string  `json:"title" binding:"required"`
-				Description *string `json:"description"`
-				Status      *string `json:"status"`
-				DueDate     *string `json:"due_date"`
-			}
-			if err := c.ShouldBindJSON(&in); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
-				return
-			}
-			uid := c.GetString("user_id")
-			t := &postgres.Task{UserID: uid, Title: in.Title}
-			if in.Description != nil {
-				t.Description = in.Description
-			}
-			if in.Status != nil {
-				t.Status = *in.Status
-			} else {
-				t.Status = "Todo"
-			}
-			if in.DueDate != nil && *in.DueDate != "" {
-				if dt, err := time.Parse(time.RFC3339, *in.DueDate); err == nil {
-					t.DueDate = &dt
-				}
-			}
-			if err := tasksRepo.Create(c.Request.Context(), t); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
-				return
-			}
-			c.JSON(http.StatusCreated, gin.H{"response_code": http.StatusCreated, "data": t})
-		})
+		sessions.GET("", h.ListSessions)
+		sessions.DELETE("/:id", h.RevokeSession)
+	}
 
-		// List Tasks
-		// @Summary List task milik user
-		// @Tags Tasks
-		// @Security BearerAuth
-		// @Produce json
-		// @Success 200 {object} map[string]interface{}
-		// @Router /api/tasks [get]
-		tasks.GET("", func(c *gin.Context) {
-			uid := c.GetString("user_id")
-			items, err := tasksRepo.ListByUser(c.Request.Context(), uid, 50, 0)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items})
-		})
+	// Tasks routes (protected)
+	tasks := r.Group("/api/tasks", authMW, RequireScopeByMethod(auth.ScopeTasksRead, auth.ScopeTasksWrite))
+	{
+		tasks.POST("", h.CreateTask)
+		tasks.GET("", h.ListTasks)
+		tasks.GET("/search", h.SearchTasks)
+		tasks.GET("/board", h.TaskBoard)
+		tasks.GET(":id", h.GetTask)
+		tasks.PUT(":id", h.UpdateTask)
+		tasks.DELETE(":id", h.DeleteTask)
+		tasks.POST(":id/transition", h.TransitionTask)
+		tasks.POST(":id/move", h.MoveTask)
+		tasks.POST(":id/labels", h.AttachTaskLabel)
+		tasks.DELETE(":id/labels/:label_id", h.DetachTaskLabel)
+		tasks.POST(":id/assignees", h.AssignTask)
+		tasks.DELETE(":id/assignees/:user_id", h.UnassignTask)
+		tasks.POST(":id/checklist", h.AddChecklistItem)
+		tasks.PATCH(":id/checklist/:item_id", h.UpdateChecklistItem)
+		tasks.DELETE(":id/checklist/:item_id", h.DeleteChecklistItem)
+		tasks.POST(":id/dependencies", h.AddTaskDependency)
+		tasks.DELETE(":id/dependencies/:depends_on_id", h.RemoveTaskDependency)
+	}
 
-		// Get Task by ID
-		// @Summary Detail task
-		// @Tags Tasks
-		// @Security BearerAuth
-		// @Produce json
-		// @Param id path string true "Task ID"
-		// @Success 200 {object} map[string]interface{}
-		// @Failure 404 {object} map[string]interface{}
-		// @Router /api/tasks/{id} [get]
-		tasks.GET(":id", func(c *gin.Context) {
-			uid := c.GetString("user_id")
-			id := c.Param("id")
-			t, err := tasksRepo.GetByID(c.Request.Context(), uid, id)
-			if err != nil {
-				c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": t})
-		})
+	// Label routes (protected)
+	labels := r.Group("/api/labels", authMW, RequireScopeByMethod(auth.ScopeTasksRead, auth.ScopeTasksWrite))
+	{
+		labels.GET("", h.ListLabels)
+		labels.POST("", h.CreateLabel)
+		labels.PATCH("/:id", h.UpdateLabel)
+		labels.DELETE("/:id", h.DeleteLabel)
+	}
 
-		// Update Task
-		// @Summary Update task
-		// @Tags Tasks
-		// @Security BearerAuth
-		// @Accept json
-		// @Produce json
-		// @Param id path string true "Task ID"
-		// @Param request body struct{Title *string `json:"title"`; Description *string `json:"description"`; Status *string `json:"status"`; DueDate *string `json:"due_date"`} true "Task update"
-		// @Success 200 {object} map[string]interface{}
-		// @Failure 400 {object} map[string]interface{}
-		// @Failure 404 {object} map[string]interface{}
-		// @Router /api/tasks/{id} [put]
-		tasks.PUT(":id", func(c *gin.Context) {
-			var in struct {
-				Title       *string `json:"title"`
-				Description *string `json:"description"`
-				Status      *string `json:"status"`
-				DueDate     *string `json:"due_date"`
-			}
-			if err := c.ShouldBindJSON(&in); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
-				return
-			}
-			uid := c.GetString("user_id")
-			id := c.Param("id")
-			t, err := tasksRepo.GetByID(c.Request.Context(), uid, id)
-			if err != nil {
-				c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
-				return
-			}
-			if in.Title != nil {
-				t.Title = *in.Title
-			}
-			if in.Description != nil {
-				t.Description = in.Description
-			}
-			if in.Status != nil {
-				t.Status = *in.Status
-			}
-			if in.DueDate != nil {
-				if *in.DueDate == "" {
-					t.DueDate = nil
-				} else if dt, err := time.Parse(time.RFC3339, *in.DueDate); err == nil {
-					t.DueDate = &dt
-				}
-			}
-			if err := tasksRepo.Update(c.Request.Context(), t); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": t})
-		})
+	// Project routes (protected)
+	projects := r.Group("/api/projects", authMW, RequireScopeByMethod(auth.ScopeTasksRead, auth.ScopeTasksWrite))
+	{
+		projects.GET("", h.ListProjects)
+		projects.POST("", h.CreateProject)
+		projects.GET("/:id", h.GetProject)
+		projects.PATCH("/:id", h.UpdateProject)
+		projects.DELETE("/:id", h.DeleteProject)
+		projects.GET("/:id/tasks", h.ListProjectTasks)
+		projects.GET("/:id/dependencies", h.ProjectDependencyGraph)
+		projects.POST("/:id/members", h.AddProjectMember)
+		projects.DELETE("/:id/members/:user_id", h.RemoveProjectMember)
+	}
 
-		// Delete Task
-		// @Summary Hapus task
-		// @Tags Tasks
-		// @Security BearerAuth
-		// @Produce json
-		// @Param id path string true "Task ID"
-		// @Success 200 {object} map[string]interface{}
-		// @Router /api/tasks/{id} [delete]
-		tasks.DELETE(":id", func(c *gin.Context) {
-			uid := c.GetString("user_id")
-			id := c.Param("id")
-			if err := tasksRepo.Delete(c.Request.Context(), uid, id); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "deleted"})
-		})
+	// Admin routes (protected, role-based)
+	admin := r.Group("/api/admin", authMW, sessionMW, RequireRole(postgres.RoleAdmin))
+	if cfg.MFARequiredForAdmin {
+		admin.Use(RequireMFA())
+	}
+	{
+		admin.GET("/tasks", RequirePermission(auth.PermTasksReadAll), h.AdminListTasks)
+		admin.GET("/tasks/:id", RequirePermission(auth.PermTasksReadAll), h.AdminGetTask)
+
+		invitations := admin.Group("/invitations", RequirePermission(auth.PermUsersManage))
+		invitations.GET("", h.AdminListInvitations)
+		invitations.POST("", h.AdminCreateInvitation)
+		invitations.DELETE("/:id", h.AdminRevokeInvitation)
+
+		users := admin.Group("/users", RequirePermission(auth.PermUsersManage))
+		users.GET("", h.AdminListUsers)
+		users.GET("/:id", h.AdminGetUser)
+		users.PATCH("/:id", h.AdminUpdateUser)
+		users.DELETE("/:id", h.AdminDeleteUser)
+		users.POST("/:id/deactivate", h.AdminDeactivateUser)
+		users.POST("/:id/reactivate", h.AdminReactivateUser)
+		users.POST("/:id/unlock", h.AdminUnlockUser)
+		users.GET("/:id/sessions", h.AdminListUserSessions)
+		users.DELETE("/:id/sessions/:sid", h.AdminRevokeUserSession)
 	}
 
 	return r
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// List Sessions godoc
+// @Summary List session login aktif milik user
+// @Tags Sessions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/sessions [get]
+func (h *Handlers) ListSessions(c *gin.Context) {
+	claims := currentClaims(c)
+	items, err := h.AuthSvc.ListSessions(c.Request.Context(), claims.Subject, claims.SessionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items})
+}
+
+// Revoke Session godoc
+// @Summary Akhiri session login (sign out perangkat lain)
+// @Tags Sessions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/sessions/{id} [delete]
+func (h *Handlers) RevokeSession(c *gin.Context) {
+	h.revokeSession(c, c.GetString("user_id"), c.Param("id"))
+}
+
+// Admin List User Sessions godoc
+// @Summary List session login aktif milik user (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/admin/users/{id}/sessions [get]
+func (h *Handlers) AdminListUserSessions(c *gin.Context) {
+	items, err := h.AuthSvc.ListSessions(c.Request.Context(), c.Param("id"), "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "data": items})
+}
+
+// Admin Revoke User Session godoc
+// @Summary Akhiri session login milik user (Admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Param sid path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/admin/users/{id}/sessions/{sid} [delete]
+func (h *Handlers) AdminRevokeUserSession(c *gin.Context) {
+	h.revokeSession(c, c.Param("id"), c.Param("sid"))
+}
+
+func (h *Handlers) revokeSession(c *gin.Context, userID, sessionID string) {
+	if err := h.AuthSvc.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"response_code": http.StatusNotFound, "error": "not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"response_code": http.StatusBadRequest, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"response_code": http.StatusOK, "message": "revoked"})
+}
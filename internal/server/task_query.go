@@ -0,0 +1,166 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend-work-mate/internal/storage/postgres"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseTaskFilter membaca query parameter filter/sort list task:
+// status (boleh berulang atau dipisah koma), due_from, due_to, overdue,
+// created_from, created_to, updated_from, updated_to, priority, label, q, sort, order.
+func parseTaskFilter(c *gin.Context) (postgres.TaskFilter, error) {
+	var f postgres.TaskFilter
+	for _, v := range c.QueryArray("status") {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				if !postgres.TaskStatus(s).Valid() {
+					return f, fmt.Errorf("invalid status: %q", s)
+				}
+				f.Statuses = append(f.Statuses, s)
+			}
+		}
+	}
+
+	for _, v := range c.QueryArray("priority") {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				if !postgres.TaskPriority(p).Valid() {
+					return f, fmt.Errorf("invalid priority: %q", p)
+				}
+				f.Priorities = append(f.Priorities, p)
+			}
+		}
+	}
+	for _, v := range c.QueryArray("label") {
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				if !validUUID(id) {
+					return f, fmt.Errorf("label tidak valid: %q bukan ID label", id)
+				}
+				f.LabelIDs = append(f.LabelIDs, id)
+			}
+		}
+	}
+
+	ranges := []struct {
+		key   string
+		dst   **time.Time
+		endOf bool
+	}{
+		{"due_from", &f.DueFrom, false},
+		{"due_to", &f.DueTo, true},
+		{"created_from", &f.CreatedFrom, false},
+		{"created_to", &f.CreatedTo, true},
+		{"updated_from", &f.UpdatedFrom, false},
+		{"updated_to", &f.UpdatedTo, true},
+	}
+	for _, r := range ranges {
+		v := c.Query(r.key)
+		if v == "" {
+			continue
+		}
+		t, err := parseTimeParam(v, r.endOf)
+		if err != nil {
+			return f, fmt.Errorf("invalid %s: %q", r.key, v)
+		}
+		*r.dst = &t
+	}
+
+	if v := c.Query("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid overdue: %q", v)
+		}
+		f.Overdue = overdue
+	}
+	f.Query = strings.TrimSpace(c.Query("q"))
+
+	f.ProjectID = strings.TrimSpace(c.Query("project_id"))
+
+	switch v := postgres.TaskView(c.Query("view")); v {
+	case postgres.TaskViewAll, postgres.TaskViewAssigned, postgres.TaskViewCreated:
+		f.View = v
+	default:
+		return f, fmt.Errorf("invalid view: %q", v)
+	}
+
+	f.Sort = c.DefaultQuery("sort", "created_at")
+	if _, ok := postgres.TaskSortFields[f.Sort]; !ok {
+		return f, fmt.Errorf("invalid sort: %q", f.Sort)
+	}
+	switch c.Query("order") {
+	case "":
+		// created_at/updated_at terbaru dan priority tertinggi lebih dulu, due_date/title naik
+		f.Desc = f.Sort == "created_at" || f.Sort == "updated_at" || f.Sort == "priority"
+	case "asc":
+		f.Desc = false
+	case "desc":
+		f.Desc = true
+	default:
+		return f, fmt.Errorf("invalid order: %q", c.Query("order"))
+	}
+	return f, nil
+}
+
+// validUUID melaporkan apakah v berformat UUID (8-4-4-4-12 digit hex), agar ID
+// yang salah ditolak sebelum sampai ke query Postgres.
+func validUUID(v string) bool {
+	if len(v) != 36 {
+		return false
+	}
+	for i, r := range v {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parsePageRequest membaca limit, cursor dan include_total untuk keyset pagination.
+func parsePageRequest(c *gin.Context) (postgres.PageRequest, error) {
+	page := postgres.PageRequest{Cursor: c.Query("cursor")}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return page, fmt.Errorf("invalid limit: %q", v)
+		}
+		page.Limit = n
+	}
+	if v := c.Query("include_total"); v != "" {
+		withTotal, err := strconv.ParseBool(v)
+		if err != nil {
+			return page, fmt.Errorf("invalid include_total: %q", v)
+		}
+		page.WithTotal = withTotal
+	}
+	return page, nil
+}
+
+// parseTimeParam menerima RFC3339 atau tanggal (YYYY-MM-DD, UTC). Untuk batas akhir
+// rentang, tanggal saja berarti sampai akhir hari tersebut.
+func parseTimeParam(v string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func filterContext(query url.Values) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/tasks?"+query.Encode(), nil)
+	return c
+}
+
+func TestParseTaskFilterLabels(t *testing.T) {
+	const a = "3f2504e0-4f89-11d3-9a0c-0305e82c3301"
+	const b = "9A7B3C1D-0E2F-4A5B-8C6D-7E8F9A0B1C2D"
+	tests := []struct {
+		name   string
+		labels []string
+		want   []string
+		err    string
+	}{
+		{"tanpa label", nil, nil, ""},
+		{"berulang dan dipisah koma", []string{a + ", " + b, a}, []string{a, b, a}, ""},
+		{"bukan UUID", []string{"abc"}, nil, `label tidak valid: "abc" bukan ID label`},
+		{"salah satu tidak valid", []string{a + ",1"}, nil, `label tidak valid: "1" bukan ID label`},
+		{"tanda hubung salah tempat", []string{"3f2504e04-f89-11d3-9a0c-0305e82c3301"}, nil,
+			`label tidak valid: "3f2504e04-f89-11d3-9a0c-0305e82c3301" bukan ID label`},
+		{"karakter non-hex", []string{"3f2504e0-4f89-11d3-9a0c-0305e82c330g"}, nil,
+			`label tidak valid: "3f2504e0-4f89-11d3-9a0c-0305e82c330g" bukan ID label`},
+	}
+	for _, tt := range tests {
+		f, err := parseTaskFilter(filterContext(url.Values{"label": tt.labels}))
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: err = %v, ingin %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: err = %v", tt.name, err)
+			continue
+		}
+		if !slices.Equal(f.LabelIDs, tt.want) {
+			t.Errorf("%s: LabelIDs = %v, ingin %v", tt.name, f.LabelIDs, tt.want)
+		}
+	}
+}
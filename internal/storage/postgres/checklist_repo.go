@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ChecklistItem adalah langkah kecil di dalam task yang cukup dicentang,
+// tanpa status dan assignee seperti subtask.
+type ChecklistItem struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"task_id"`
+	Title     string    `json:"title"`
+	Done      bool      `json:"done"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChecklistRepository tidak memeriksa akses ke task; itu tugas pemanggil.
+type ChecklistRepository interface {
+	Create(ctx context.Context, item *ChecklistItem) error
+	GetByID(ctx context.Context, taskID, id string) (*ChecklistItem, error)
+	Update(ctx context.Context, item *ChecklistItem) error
+	Delete(ctx context.Context, taskID, id string) error
+}
+
+type checklistRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewChecklistRepository(pool *pgxpool.Pool) ChecklistRepository {
+	return &checklistRepository{pool: pool}
+}
+
+const checklistColumns = `id, task_id, title, done, position, created_at, updated_at`
+
+func scanChecklistItem(row pgx.Row) (*ChecklistItem, error) {
+	var c ChecklistItem
+	if err := row.Scan(&c.ID, &c.TaskID, &c.Title, &c.Done, &c.Position, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Create menambah item di akhir checklist task.
+func (r *checklistRepository) Create(ctx context.Context, item *ChecklistItem) error {
+	const q = `insert into public.checklist_items (task_id, title, done, position)
+               values ($1, $2, $3, (select coalesce(max(position), 0) + 1 from public.checklist_items where task_id = $1))
+               returning id, position, created_at, updated_at`
+	return r.pool.QueryRow(ctx, q, item.TaskID, item.Title, item.Done).Scan(&item.ID, &item.Position, &item.CreatedAt, &item.UpdatedAt)
+}
+
+func (r *checklistRepository) GetByID(ctx context.Context, taskID, id string) (*ChecklistItem, error) {
+	q := `select ` + checklistColumns + ` from public.checklist_items where id=$1 and task_id=$2`
+	item, err := scanChecklistItem(r.pool.QueryRow(ctx, q, id, taskID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return item, err
+}
+
+func (r *checklistRepository) Update(ctx context.Context, item *ChecklistItem) error {
+	const q = `update public.checklist_items set title=$1, done=$2, updated_at=now()
+               where id=$3 and task_id=$4 returning updated_at`
+	return r.pool.QueryRow(ctx, q, item.Title, item.Done, item.ID, item.TaskID).Scan(&item.UpdatedAt)
+}
+
+func (r *checklistRepository) Delete(ctx context.Context, taskID, id string) error {
+	const q = `delete from public.checklist_items where id=$1 and task_id=$2`
+	tag, err := r.pool.Exec(ctx, q, id, taskID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// listChecklist dipakai repository task untuk mengisi detail task.
+func listChecklist(ctx context.Context, pool *pgxpool.Pool, taskID string) ([]ChecklistItem, error) {
+	q := `select ` + checklistColumns + ` from public.checklist_items where task_id=$1 order by position, created_at`
+	rows, err := pool.Query(ctx, q, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChecklistItem{}
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Invitation adalah undangan dari Admin untuk membuat akun dengan email, role
+// dan department yang sudah ditentukan. Token hanya disimpan dalam bentuk hash.
+type Invitation struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	Role       UserRole   `json:"role"`
+	Department *string    `json:"department,omitempty"`
+	TokenHash  string     `json:"-"`
+	InvitedBy  *string    `json:"invited_by,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type InvitationRepository interface {
+	Create(ctx context.Context, inv *Invitation) error
+	GetByHash(ctx context.Context, tokenHash string) (*Invitation, error)
+	List(ctx context.Context, pendingOnly bool, limit, offset int) ([]Invitation, int, error)
+	Accept(ctx context.Context, tokenHash string) (*Invitation, error)
+	Revoke(ctx context.Context, id string) error
+	RevokePendingForEmail(ctx context.Context, email string) error
+}
+
+type invitationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewInvitationRepository(pool *pgxpool.Pool) InvitationRepository {
+	return &invitationRepository{pool: pool}
+}
+
+const invitationColumns = `id, email, role, department, token_hash, invited_by, expires_at, accepted_at, revoked_at, created_at`
+
+const invitationPending = `accepted_at is null and revoked_at is null and expires_at > now()`
+
+func scanInvitation(row pgx.Row) (*Invitation, error) {
+	var inv Invitation
+	if err := row.Scan(&inv.ID, &inv.Email, &inv.Role, &inv.Department, &inv.TokenHash, &inv.InvitedBy,
+		&inv.ExpiresAt, &inv.AcceptedAt, &inv.RevokedAt, &inv.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *invitationRepository) Create(ctx context.Context, inv *Invitation) error {
+	const q = `insert into public.invitations (email, role, department, token_hash, invited_by, expires_at)
+               values ($1, $2, $3, $4, $5, $6)
+               returning id, created_at`
+	return r.pool.QueryRow(ctx, q, inv.Email, inv.Role, inv.Department, inv.TokenHash, inv.InvitedBy, inv.ExpiresAt).
+		Scan(&inv.ID, &inv.CreatedAt)
+}
+
+// GetByHash mengembalikan undangan yang masih berlaku, atau nil.
+func (r *invitationRepository) GetByHash(ctx context.Context, tokenHash string) (*Invitation, error) {
+	q := `select ` + invitationColumns + ` from public.invitations where token_hash=$1 and ` + invitationPending
+	inv, err := scanInvitation(r.pool.QueryRow(ctx, q, tokenHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return inv, err
+}
+
+func (r *invitationRepository) List(ctx context.Context, pendingOnly bool, limit, offset int) ([]Invitation, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	cond := ""
+	if pendingOnly {
+		cond = " where " + invitationPending
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `select count(*) from public.invitations`+cond).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	q := `select ` + invitationColumns + ` from public.invitations` + cond + ` order by created_at desc limit $1 offset $2`
+	rows, err := r.pool.Query(ctx, q, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var invitations []Invitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		invitations = append(invitations, *inv)
+	}
+	return invitations, total, rows.Err()
+}
+
+// Accept menandai undangan sebagai diterima secara atomik dan mengembalikannya.
+// Mengembalikan nil bila token tidak valid, kedaluwarsa, dicabut atau sudah dipakai.
+func (r *invitationRepository) Accept(ctx context.Context, tokenHash string) (*Invitation, error) {
+	q := `update public.invitations set accepted_at=now()
+          where token_hash=$1 and ` + invitationPending + `
+          returning ` + invitationColumns
+	inv, err := scanInvitation(r.pool.QueryRow(ctx, q, tokenHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return inv, err
+}
+
+func (r *invitationRepository) Revoke(ctx context.Context, id string) error {
+	const q = `update public.invitations set revoked_at=now()
+               where id=$1 and accepted_at is null and revoked_at is null`
+	tag, err := r.pool.Exec(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *invitationRepository) RevokePendingForEmail(ctx context.Context, email string) error {
+	const q = `update public.invitations set revoked_at=now()
+               where email=$1 and accepted_at is null and revoked_at is null`
+	_, err := r.pool.Exec(ctx, q, email)
+	return err
+}
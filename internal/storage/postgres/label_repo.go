@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Label adalah label berwarna milik user yang bisa dipasang ke banyak task.
+type Label struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LabelRepository interface {
+	Create(ctx context.Context, l *Label) error
+	GetByID(ctx context.Context, userID, id string) (*Label, error)
+	ListByUser(ctx context.Context, userID string) ([]Label, error)
+	Update(ctx context.Context, l *Label) error
+	Delete(ctx context.Context, userID, id string) error
+	Attach(ctx context.Context, taskID, labelID string) error
+	Detach(ctx context.Context, taskID, labelID string) error
+}
+
+type labelRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLabelRepository(pool *pgxpool.Pool) LabelRepository {
+	return &labelRepository{pool: pool}
+}
+
+const labelColumns = `id, user_id, name, color, created_at`
+
+func scanLabel(row pgx.Row) (*Label, error) {
+	var l Label
+	if err := row.Scan(&l.ID, &l.UserID, &l.Name, &l.Color, &l.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *labelRepository) Create(ctx context.Context, l *Label) error {
+	const q = `insert into public.labels (user_id, name, color) values ($1, $2, $3)
+               returning id, created_at`
+	return r.pool.QueryRow(ctx, q, l.UserID, l.Name, l.Color).Scan(&l.ID, &l.CreatedAt)
+}
+
+func (r *labelRepository) GetByID(ctx context.Context, userID, id string) (*Label, error) {
+	q := `select ` + labelColumns + ` from public.labels where id=$1 and user_id=$2`
+	l, err := scanLabel(r.pool.QueryRow(ctx, q, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return l, err
+}
+
+func (r *labelRepository) ListByUser(ctx context.Context, userID string) ([]Label, error) {
+	q := `select ` + labelColumns + ` from public.labels where user_id=$1 order by lower(name)`
+	rows, err := r.pool.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var labels []Label
+	for rows.Next() {
+		l, err := scanLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, *l)
+	}
+	return labels, rows.Err()
+}
+
+func (r *labelRepository) Update(ctx context.Context, l *Label) error {
+	const q = `update public.labels set name=$1, color=$2 where id=$3 and user_id=$4`
+	tag, err := r.pool.Exec(ctx, q, l.Name, l.Color, l.ID, l.UserID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *labelRepository) Delete(ctx context.Context, userID, id string) error {
+	const q = `delete from public.labels where id=$1 and user_id=$2`
+	tag, err := r.pool.Exec(ctx, q, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Attach memasang label ke task; memasang label yang sudah terpasang tidak error.
+// Kepemilikan task dan label dicek oleh pemanggil.
+func (r *labelRepository) Attach(ctx context.Context, taskID, labelID string) error {
+	const q = `insert into public.task_labels (task_id, label_id) values ($1, $2) on conflict do nothing`
+	_, err := r.pool.Exec(ctx, q, taskID, labelID)
+	return err
+}
+
+func (r *labelRepository) Detach(ctx context.Context, taskID, labelID string) error {
+	const q = `delete from public.task_labels where task_id=$1 and label_id=$2`
+	tag, err := r.pool.Exec(ctx, q, taskID, labelID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
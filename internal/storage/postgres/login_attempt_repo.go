@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LoginAttempt mencatat kegagalan login berturut-turut untuk sebuah key,
+// mis. "email:budi@example.com" atau "ip:10.0.0.1".
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	LockedUntil   *time.Time
+	LastFailureAt time.Time
+}
+
+type LoginAttemptRepository interface {
+	Get(ctx context.Context, key string) (*LoginAttempt, error)
+	RecordFailure(ctx context.Context, key string, window time.Duration) (*LoginAttempt, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type loginAttemptRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLoginAttemptRepository(pool *pgxpool.Pool) LoginAttemptRepository {
+	return &loginAttemptRepository{pool: pool}
+}
+
+func (r *loginAttemptRepository) Get(ctx context.Context, key string) (*LoginAttempt, error) {
+	const q = `select key, failures, locked_until, last_failure_at from public.login_attempts where key=$1`
+	var a LoginAttempt
+	if err := r.pool.QueryRow(ctx, q, key).Scan(&a.Key, &a.Failures, &a.LockedUntil, &a.LastFailureAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+// RecordFailure menambah hitungan kegagalan secara atomik. Hitungan dimulai ulang
+// bila kegagalan terakhir (atau berakhirnya kunci, bila lebih akhir) lebih lama
+// dari window, sehingga kunci yang lebih panjang dari window tetap berlipat.
+func (r *loginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*LoginAttempt, error) {
+	const q = `insert into public.login_attempts (key, failures, last_failure_at) values ($1, 1, now())
+               on conflict (key) do update set
+                 failures = case when greatest(public.login_attempts.last_failure_at, public.login_attempts.locked_until)
+                                      < now() - make_interval(secs => $2)
+                                 then 1 else public.login_attempts.failures + 1 end,
+                 last_failure_at = now()
+               returning key, failures, locked_until, last_failure_at`
+	var a LoginAttempt
+	if err := r.pool.QueryRow(ctx, q, key, window.Seconds()).Scan(&a.Key, &a.Failures, &a.LockedUntil, &a.LastFailureAt); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *loginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.pool.Exec(ctx, `update public.login_attempts set locked_until=$2 where key=$1`, key, until)
+	return err
+}
+
+func (r *loginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.pool.Exec(ctx, `delete from public.login_attempts where key=$1`, key)
+	return err
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UserMFA adalah konfigurasi TOTP milik user. EnabledAt nil berarti enrolment
+// belum dikonfirmasi dengan kode yang valid.
+type UserMFA struct {
+	UserID       string
+	Secret       string
+	EnabledAt    *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+type MFARepository interface {
+	Get(ctx context.Context, userID string) (*UserMFA, error)
+	SavePending(ctx context.Context, userID, secret string) error
+	Enable(ctx context.Context, userID string) error
+	Delete(ctx context.Context, userID string) error
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+}
+
+type mfaRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMFARepository(pool *pgxpool.Pool) MFARepository {
+	return &mfaRepository{pool: pool}
+}
+
+func (r *mfaRepository) Get(ctx context.Context, userID string) (*UserMFA, error) {
+	const q = `select user_id, secret, enabled_at, last_used_step, created_at
+               from public.user_mfa where user_id=$1`
+	var m UserMFA
+	if err := r.pool.QueryRow(ctx, q, userID).Scan(&m.UserID, &m.Secret, &m.EnabledAt, &m.LastUsedStep, &m.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SavePending menyimpan secret baru yang belum aktif, menggantikan enrolment sebelumnya.
+func (r *mfaRepository) SavePending(ctx context.Context, userID, secret string) error {
+	const q = `insert into public.user_mfa (user_id, secret) values ($1, $2)
+               on conflict (user_id) do update set secret=excluded.secret, enabled_at=null,
+                 last_used_step=0, created_at=now()`
+	_, err := r.pool.Exec(ctx, q, userID, secret)
+	return err
+}
+
+func (r *mfaRepository) Enable(ctx context.Context, userID string) error {
+	_, err := r.pool.Exec(ctx, `update public.user_mfa set enabled_at=now() where user_id=$1`, userID)
+	return err
+}
+
+func (r *mfaRepository) Delete(ctx context.Context, userID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `delete from public.mfa_recovery_codes where user_id=$1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `delete from public.user_mfa where user_id=$1`, userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UseStep mencatat langkah waktu TOTP yang dipakai. Mengembalikan false bila
+// langkah tersebut (atau yang lebih baru) sudah pernah dipakai, sehingga kode yang
+// sama tidak bisa dipakai dua kali.
+func (r *mfaRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	const q = `update public.user_mfa set last_used_step=$2 where user_id=$1 and last_used_step < $2`
+	tag, err := r.pool.Exec(ctx, q, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `delete from public.mfa_recovery_codes where user_id=$1`, userID); err != nil {
+		return err
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.Exec(ctx, `insert into public.mfa_recovery_codes (user_id, code_hash) values ($1, $2)`, userID, h); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	const q = `update public.mfa_recovery_codes set used_at=now()
+               where user_id=$1 and code_hash=$2 and used_at is null`
+	tag, err := r.pool.Exec(ctx, q, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *mfaRepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	const q = `select count(*) from public.mfa_recovery_codes where user_id=$1 and used_at is null`
+	var n int
+	err := r.pool.QueryRow(ctx, q, userID).Scan(&n)
+	return n, err
+}
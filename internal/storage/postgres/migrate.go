@@ -49,6 +49,235 @@ end$$;`,
 		`create index if not exists tasks_user_id_idx on public.tasks (user_id);`,
 		`create index if not exists tasks_status_idx on public.tasks (status);`,
 		`create index if not exists tasks_due_date_idx on public.tasks (due_date);`,
+		// refresh tokens (disimpan sebagai hash, dirotasi per family)
+		`create table if not exists public.refresh_tokens (
+  id          uuid        primary key default gen_random_uuid(),
+  user_id     uuid        not null references public.users(id) on delete cascade,
+  family_id   uuid        not null,
+  token_hash  text        not null unique,
+  expires_at  timestamptz not null,
+  used_at     timestamptz,
+  revoked_at  timestamptz,
+  created_at  timestamptz not null default now()
+);`,
+		`create index if not exists refresh_tokens_user_id_idx on public.refresh_tokens (user_id);`,
+		`create index if not exists refresh_tokens_family_id_idx on public.refresh_tokens (family_id);`,
+		// revocation: versi token per user dan denylist jti
+		`alter table public.users add column if not exists token_version integer not null default 0;`,
+		`create table if not exists public.revoked_tokens (
+  jti         text        primary key,
+  expires_at  timestamptz not null
+);`,
+		`create index if not exists revoked_tokens_expires_at_idx on public.revoked_tokens (expires_at);`,
+		// status akun untuk manajemen user oleh Admin
+		`alter table public.users add column if not exists is_active boolean not null default true;`,
+		`alter table public.users add column if not exists updated_at timestamptz not null default now();`,
+		// token sekali pakai via email (reset password, dsb.)
+		`create table if not exists public.user_tokens (
+  id          uuid        primary key default gen_random_uuid(),
+  user_id     uuid        not null references public.users(id) on delete cascade,
+  purpose     text        not null,
+  token_hash  text        not null unique,
+  expires_at  timestamptz not null,
+  used_at     timestamptz,
+  created_at  timestamptz not null default now()
+);`,
+		`create index if not exists user_tokens_user_id_idx on public.user_tokens (user_id, purpose);`,
+		// verifikasi email; user lama dianggap sudah terverifikasi
+		`do $$
+begin
+  if not exists (select 1 from information_schema.columns where table_schema='public' and table_name='users' and column_name='email_verified_at') then
+    alter table public.users add column email_verified_at timestamptz;
+    update public.users set email_verified_at = created_at;
+  end if;
+end$$;`,
+		// TOTP two-factor authentication
+		`create table if not exists public.user_mfa (
+  user_id         uuid        primary key references public.users(id) on delete cascade,
+  secret          text        not null,
+  enabled_at      timestamptz,
+  last_used_step  bigint      not null default 0,
+  created_at      timestamptz not null default now()
+);`,
+		`create table if not exists public.mfa_recovery_codes (
+  id          uuid        primary key default gen_random_uuid(),
+  user_id     uuid        not null references public.users(id) on delete cascade,
+  code_hash   text        not null,
+  used_at     timestamptz,
+  created_at  timestamptz not null default now()
+);`,
+		`create index if not exists mfa_recovery_codes_user_id_idx on public.mfa_recovery_codes (user_id);`,
+		`alter table public.refresh_tokens add column if not exists mfa boolean not null default false;`,
+		// proteksi brute-force login (per email dan per IP)
+		`create table if not exists public.login_attempts (
+  key              text        primary key,
+  failures         integer     not null default 0,
+  locked_until     timestamptz,
+  last_failure_at  timestamptz not null default now()
+);`,
+		// personal access tokens untuk script dan integrasi
+		`create table if not exists public.personal_access_tokens (
+  id            uuid        primary key default gen_random_uuid(),
+  user_id       uuid        not null references public.users(id) on delete cascade,
+  name          text        not null,
+  prefix        text        not null,
+  token_hash    text        not null unique,
+  scopes        text[]      not null,
+  expires_at    timestamptz,
+  last_used_at  timestamptz,
+  revoked_at    timestamptz,
+  created_at    timestamptz not null default now()
+);`,
+		`create index if not exists personal_access_tokens_user_id_idx on public.personal_access_tokens (user_id);`,
+		// state sementara untuk login OIDC (authorization code + PKCE)
+		`create table if not exists public.oidc_states (
+  state_hash     text        primary key,
+  nonce          text        not null,
+  code_verifier  text        not null,
+  expires_at     timestamptz not null
+);`,
+		// session login per perangkat; id dipakai sebagai family_id refresh token.
+		// Family refresh token yang masih aktif dijadikan session saat tabel dibuat.
+		`do $$
+begin
+  if not exists (select 1 from information_schema.tables where table_schema='public' and table_name='sessions') then
+    create table public.sessions (
+      id            uuid        primary key default gen_random_uuid(),
+      user_id       uuid        not null references public.users(id) on delete cascade,
+      user_agent    text        not null default '',
+      ip            text        not null default '',
+      created_at    timestamptz not null default now(),
+      last_seen_at  timestamptz not null default now(),
+      expires_at    timestamptz not null,
+      revoked_at    timestamptz
+    );
+    insert into public.sessions (id, user_id, created_at, last_seen_at, expires_at)
+    select family_id, min(user_id::text)::uuid, min(created_at), max(created_at), max(expires_at)
+    from public.refresh_tokens
+    where revoked_at is null
+    group by family_id
+    having max(expires_at) > now();
+  end if;
+end$$;`,
+		`create index if not exists sessions_user_id_idx on public.sessions (user_id);`,
+		// undangan akun dari Admin
+		`create table if not exists public.invitations (
+  id           uuid        primary key default gen_random_uuid(),
+  email        citext      not null,
+  role         user_role   not null default 'Employee',
+  department   text,
+  token_hash   text        not null unique,
+  invited_by   uuid        references public.users(id) on delete set null,
+  expires_at   timestamptz not null,
+  accepted_at  timestamptz,
+  revoked_at   timestamptz,
+  created_at   timestamptz not null default now()
+);`,
+		`create index if not exists invitations_email_idx on public.invitations (email);`,
+		// full-text search task; konfigurasi 'simple' karena isi task campuran bahasa
+		`alter table public.tasks add column if not exists search_vector tsvector
+  generated always as (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+  ) stored;`,
+		`create index if not exists tasks_search_vector_idx on public.tasks using gin (search_vector);`,
+		// status task sebagai workflow: normalisasi data lama lalu batasi nilainya
+		`alter table public.tasks add column if not exists completed_at timestamptz;`,
+		`do $$
+begin
+  if not exists (select 1 from pg_constraint where conname = 'tasks_status_check') then
+    update public.tasks set status = case
+      when lower(trim(status)) in ('todo', 'to do', 'to-do', 'open', 'new') then 'Todo'
+      when lower(trim(status)) in ('in progress', 'in_progress', 'in-progress', 'inprogress', 'doing', 'progress') then 'In Progress'
+      when lower(trim(status)) in ('blocked', 'on hold') then 'Blocked'
+      when lower(trim(status)) in ('done', 'complete', 'completed', 'finished', 'selesai') then 'Done'
+      when lower(trim(status)) in ('cancelled', 'canceled', 'cancel', 'dibatalkan') then 'Cancelled'
+      else 'Todo'
+    end;
+    update public.tasks set completed_at = updated_at where status = 'Done' and completed_at is null;
+    alter table public.tasks add constraint tasks_status_check
+      check (status in ('Todo', 'In Progress', 'Blocked', 'Done', 'Cancelled'));
+  end if;
+end$$;`,
+		// prioritas dan label task
+		`alter table public.tasks add column if not exists priority text not null default 'Medium'
+  constraint tasks_priority_check check (priority in ('Low', 'Medium', 'High', 'Urgent'));`,
+		`create index if not exists tasks_priority_idx on public.tasks (priority);`,
+		`create table if not exists public.labels (
+  id          uuid        primary key default gen_random_uuid(),
+  user_id     uuid        not null references public.users(id) on delete cascade,
+  name        text        not null,
+  color       text        not null,
+  created_at  timestamptz not null default now()
+);`,
+		`create unique index if not exists labels_user_name_idx on public.labels (user_id, lower(name));`,
+		`create table if not exists public.task_labels (
+  task_id   uuid not null references public.tasks(id) on delete cascade,
+  label_id  uuid not null references public.labels(id) on delete cascade,
+  primary key (task_id, label_id)
+);`,
+		`create index if not exists task_labels_label_id_idx on public.task_labels (label_id);`,
+		// assignee task (bisa lebih dari satu, terpisah dari pembuat task)
+		`create table if not exists public.task_assignees (
+  task_id      uuid        not null references public.tasks(id) on delete cascade,
+  user_id      uuid        not null references public.users(id) on delete cascade,
+  assigned_by  uuid        references public.users(id) on delete set null,
+  assigned_at  timestamptz not null default now(),
+  primary key (task_id, user_id)
+);`,
+		`create index if not exists task_assignees_user_id_idx on public.task_assignees (user_id);`,
+		// project untuk mengelompokkan task; anggota project bisa melihat dan mengubah task di dalamnya
+		`create table if not exists public.projects (
+  id           uuid        primary key default gen_random_uuid(),
+  owner_id     uuid        not null references public.users(id) on delete cascade,
+  name         text        not null,
+  description  text,
+  archived     boolean     not null default false,
+  created_at   timestamptz not null default now(),
+  updated_at   timestamptz not null default now()
+);`,
+		`create index if not exists projects_owner_id_idx on public.projects (owner_id);`,
+		`create table if not exists public.project_members (
+  project_id  uuid        not null references public.projects(id) on delete cascade,
+  user_id     uuid        not null references public.users(id) on delete cascade,
+  added_at    timestamptz not null default now(),
+  primary key (project_id, user_id)
+);`,
+		`create index if not exists project_members_user_id_idx on public.project_members (user_id);`,
+		// task tetap ada (tanpa project) saat project-nya dihapus
+		`alter table public.tasks add column if not exists project_id uuid references public.projects(id) on delete set null;`,
+		`create index if not exists tasks_project_id_idx on public.tasks (project_id);`,
+		// posisi task di kolom kanban; collation "C" agar urutan string = urutan byte
+		`alter table public.tasks add column if not exists position text collate "C";`,
+		`update public.tasks t set position = r.pos
+   from (select id, lpad((row_number() over (partition by status order by created_at, id))::text, 10, '0') as pos
+           from public.tasks where position is null) r
+  where t.id = r.id;`,
+		`alter table public.tasks alter column position set not null;`,
+		`create index if not exists tasks_status_position_idx on public.tasks (status, position);`,
+		// subtask: menghapus parent ikut menghapus subtask-nya
+		`alter table public.tasks add column if not exists parent_id uuid references public.tasks(id) on delete cascade;`,
+		`create index if not exists tasks_parent_id_idx on public.tasks (parent_id);`,
+		`create table if not exists public.checklist_items (
+  id          uuid        primary key default gen_random_uuid(),
+  task_id     uuid        not null references public.tasks(id) on delete cascade,
+  title       text        not null,
+  done        boolean     not null default false,
+  position    int         not null,
+  created_at  timestamptz not null default now(),
+  updated_at  timestamptz not null default now()
+);`,
+		`create index if not exists checklist_items_task_id_idx on public.checklist_items (task_id, position);`,
+		// task_id diblokir oleh depends_on_id; siklus dicegah di aplikasi
+		`create table if not exists public.task_dependencies (
+  task_id        uuid        not null references public.tasks(id) on delete cascade,
+  depends_on_id  uuid        not null references public.tasks(id) on delete cascade,
+  created_by     uuid        references public.users(id) on delete set null,
+  created_at     timestamptz not null default now(),
+  primary key (task_id, depends_on_id),
+  check (task_id <> depends_on_id)
+);`,
+		`create index if not exists task_dependencies_depends_on_id_idx on public.task_dependencies (depends_on_id);`,
 	}
 	sql := strings.Join(stmts, "\n")
 	if _, err := pool.Exec(ctx, sql); err != nil {
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OIDCState menyimpan nonce dan PKCE verifier selama alur login OIDC berlangsung.
+type OIDCState struct {
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+type OIDCStateRepository interface {
+	Create(ctx context.Context, s *OIDCState) error
+	Consume(ctx context.Context, stateHash string) (*OIDCState, error)
+}
+
+type oidcStateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOIDCStateRepository(pool *pgxpool.Pool) OIDCStateRepository {
+	return &oidcStateRepository{pool: pool}
+}
+
+func (r *oidcStateRepository) Create(ctx context.Context, s *OIDCState) error {
+	// bersihkan state kedaluwarsa dari login yang tidak pernah selesai
+	if _, err := r.pool.Exec(ctx, `delete from public.oidc_states where expires_at < now()`); err != nil {
+		return err
+	}
+	const q = `insert into public.oidc_states (state_hash, nonce, code_verifier, expires_at) values ($1, $2, $3, $4)`
+	_, err := r.pool.Exec(ctx, q, s.StateHash, s.Nonce, s.CodeVerifier, s.ExpiresAt)
+	return err
+}
+
+// Consume menghapus dan mengembalikan state (sekali pakai). Nil bila tidak ada atau kedaluwarsa.
+func (r *oidcStateRepository) Consume(ctx context.Context, stateHash string) (*OIDCState, error) {
+	const q = `delete from public.oidc_states where state_hash=$1
+               returning state_hash, nonce, code_verifier, expires_at`
+	var s OIDCState
+	if err := r.pool.QueryRow(ctx, q, stateHash).Scan(&s.StateHash, &s.Nonce, &s.CodeVerifier, &s.ExpiresAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if time.Now().After(s.ExpiresAt) {
+		return nil, nil
+	}
+	return &s, nil
+}
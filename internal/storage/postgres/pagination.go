@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("cursor tidak valid")
+
+// PageRequest adalah permintaan satu halaman keyset pagination. Cursor kosong
+// berarti halaman pertama.
+type PageRequest struct {
+	Limit     int
+	Cursor    string
+	WithTotal bool
+}
+
+// PageInfo adalah metadata pagination yang dikirim ke client.
+type PageInfo struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int   `json:"total,omitempty"`
+}
+
+func (p PageRequest) limit() int {
+	switch {
+	case p.Limit <= 0:
+		return DefaultPageSize
+	case p.Limit > MaxPageSize:
+		return MaxPageSize
+	}
+	return p.Limit
+}
+
+// cursor menunjuk posisi sebuah baris pada urutan tertentu. Sort dan Desc ikut
+// disimpan agar cursor tidak dipakai dengan urutan yang berbeda.
+type cursor struct {
+	Sort     string  `json:"s"`
+	Desc     bool    `json:"d,omitempty"`
+	Value    *string `json:"v"`
+	ID       string  `json:"id"`
+	Backward bool    `json:"b,omitempty"`
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// offsetCursor dipakai untuk hasil yang urutannya tidak stabil untuk keyset
+// (mis. ranking search); isinya tetap opaque bagi client.
+type offsetCursor struct {
+	Offset int `json:"o"`
+}
+
+func (c offsetCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(`{"o":` + strconv.Itoa(c.Offset) + `}`))
+}
+
+func decodeOffsetCursor(s string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	var c offsetCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return c.Offset, nil
+}
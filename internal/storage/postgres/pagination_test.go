@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPageRequestLimit(t *testing.T) {
+	tests := []struct{ in, want int }{
+		{0, DefaultPageSize},
+		{-5, DefaultPageSize},
+		{1, 1},
+		{MaxPageSize, MaxPageSize},
+		{MaxPageSize + 1, MaxPageSize},
+	}
+	for _, tt := range tests {
+		if got := (PageRequest{Limit: tt.in}).limit(); got != tt.want {
+			t.Errorf("limit(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	v := "2024-05-01T10:00:00Z"
+	tests := []cursor{
+		{Sort: "created_at", Value: &v, ID: "11111111-1111-1111-1111-111111111111"},
+		{Sort: "due_date", Desc: true, Value: nil, ID: "22222222-2222-2222-2222-222222222222"},
+		{Sort: "title", Value: &v, ID: "33333333-3333-3333-3333-333333333333", Backward: true},
+	}
+	for _, c := range tests {
+		got, err := decodeCursor(c.encode())
+		if err != nil {
+			t.Fatalf("decodeCursor(%+v): %v", c, err)
+		}
+		if !reflect.DeepEqual(*got, c) {
+			t.Errorf("round trip = %+v, want %+v", *got, c)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsTampered(t *testing.T) {
+	valid := cursor{Sort: "created_at", ID: "11111111-1111-1111-1111-111111111111"}.encode()
+	enc := base64.RawURLEncoding.EncodeToString
+	tests := map[string]string{
+		"kosong":        "",
+		"bukan base64":  "!!!",
+		"padding std":   valid + "==",
+		"terpotong":     valid[:len(valid)-3],
+		"bukan json":    enc([]byte("not json")),
+		"tanpa id":      enc([]byte(`{"s":"created_at","v":null}`)),
+		"tipe salah":    enc([]byte(`{"s":1,"id":"x"}`)),
+		"offset cursor": offsetCursor{Offset: 20}.encode(),
+	}
+	for name, s := range tests {
+		if _, err := decodeCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: err = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+func TestOffsetCursor(t *testing.T) {
+	for _, n := range []int{0, 20, 1000} {
+		got, err := decodeOffsetCursor(offsetCursor{Offset: n}.encode())
+		if err != nil || got != n {
+			t.Errorf("round trip %d = %d, %v", n, got, err)
+		}
+	}
+	enc := base64.RawURLEncoding.EncodeToString
+	for _, s := range []string{"!!!", enc([]byte(`{"o":-1}`)), enc([]byte(`{"o":"x"}`))} {
+		if _, err := decodeOffsetCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeOffsetCursor(%q) err = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PersonalAccessToken adalah token berumur panjang untuk script/integrasi.
+// Hanya hash yang disimpan; Prefix dipakai untuk menampilkan token di UI.
+type PersonalAccessToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	TokenHash  string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, t *PersonalAccessToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*PersonalAccessToken, error)
+	ListByUser(ctx context.Context, userID string) ([]PersonalAccessToken, error)
+	Revoke(ctx context.Context, userID, id string) error
+	Touch(ctx context.Context, id string) error
+}
+
+type patRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPersonalAccessTokenRepository(pool *pgxpool.Pool) PersonalAccessTokenRepository {
+	return &patRepository{pool: pool}
+}
+
+const patColumns = `id, user_id, name, prefix, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func scanPAT(row pgx.Row) (*PersonalAccessToken, error) {
+	var t PersonalAccessToken
+	if err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, &t.TokenHash, &t.Scopes, &t.ExpiresAt, &t.LastUsedAt, &t.RevokedAt, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *patRepository) Create(ctx context.Context, t *PersonalAccessToken) error {
+	const q = `insert into public.personal_access_tokens (user_id, name, prefix, token_hash, scopes, expires_at)
+               values ($1, $2, $3, $4, $5, $6)
+               returning id, created_at`
+	return r.pool.QueryRow(ctx, q, t.UserID, t.Name, t.Prefix, t.TokenHash, t.Scopes, t.ExpiresAt).Scan(&t.ID, &t.CreatedAt)
+}
+
+func (r *patRepository) GetByHash(ctx context.Context, tokenHash string) (*PersonalAccessToken, error) {
+	q := `select ` + patColumns + ` from public.personal_access_tokens where token_hash=$1`
+	t, err := scanPAT(r.pool.QueryRow(ctx, q, tokenHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (r *patRepository) ListByUser(ctx context.Context, userID string) ([]PersonalAccessToken, error) {
+	q := `select ` + patColumns + ` from public.personal_access_tokens
+          where user_id=$1 order by created_at desc`
+	rows, err := r.pool.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tokens []PersonalAccessToken
+	for rows.Next() {
+		t, err := scanPAT(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *t)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *patRepository) Revoke(ctx context.Context, userID, id string) error {
+	const q = `update public.personal_access_tokens set revoked_at=now()
+               where id=$1 and user_id=$2 and revoked_at is null`
+	tag, err := r.pool.Exec(ctx, q, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Touch memperbarui last_used_at, paling sering sekali per menit per token.
+func (r *patRepository) Touch(ctx context.Context, id string) error {
+	const q = `update public.personal_access_tokens set last_used_at=now()
+               where id=$1 and (last_used_at is null or last_used_at < now() - interval '1 minute')`
+	_, err := r.pool.Exec(ctx, q, id)
+	return err
+}
@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Project mengelompokkan task. Owner dan anggota bisa melihat serta mengubah task
+// di dalamnya; project yang diarsipkan tidak bisa menerima task baru.
+type Project struct {
+	ID          string    `json:"id"`
+	OwnerID     string    `json:"owner_id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	Archived    bool      `json:"archived"`
+	OpenTasks   int       `json:"open_tasks"`
+	DoneTasks   int       `json:"done_tasks"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// Members hanya diisi oleh GetByID.
+	Members []ProjectMember `json:"members,omitempty"`
+}
+
+type ProjectMember struct {
+	UserID  string    `json:"user_id"`
+	Name    string    `json:"name"`
+	Email   string    `json:"email"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type ProjectRepository interface {
+	Create(ctx context.Context, p *Project) error
+	GetByID(ctx context.Context, userID, id string) (*Project, error)
+	IsArchived(ctx context.Context, id string) (bool, error)
+	ListByUser(ctx context.Context, userID string, includeArchived bool) ([]Project, error)
+	Update(ctx context.Context, p *Project) error
+	Delete(ctx context.Context, ownerID, id string) error
+	AddMember(ctx context.Context, projectID, userID string) error
+	RemoveMember(ctx context.Context, projectID, userID string) error
+}
+
+type projectRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewProjectRepository(pool *pgxpool.Pool) ProjectRepository {
+	return &projectRepository{pool: pool}
+}
+
+// projectColumns menghitung task terbuka (belum Done/Cancelled) dan selesai per project.
+const projectColumns = `p.id, p.owner_id, p.name, p.description, p.archived,
+  (select count(*) from public.tasks t where t.project_id = p.id and t.status not in ('Done', 'Cancelled')),
+  (select count(*) from public.tasks t where t.project_id = p.id and t.status = 'Done'),
+  p.created_at, p.updated_at`
+
+func scanProject(row pgx.Row) (*Project, error) {
+	var p Project
+	if err := row.Scan(&p.ID, &p.OwnerID, &p.Name, &p.Description, &p.Archived, &p.OpenTasks, &p.DoneTasks, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// projectVisibleTo adalah kondisi project (alias p) yang boleh dilihat user ($n): owner atau anggota.
+func projectVisibleTo(n int) string {
+	return fmt.Sprintf("(p.owner_id = $%[1]d or exists (select 1 from public.project_members m where m.project_id = p.id and m.user_id = $%[1]d))", n)
+}
+
+func (r *projectRepository) Create(ctx context.Context, p *Project) error {
+	const q = `insert into public.projects (owner_id, name, description) values ($1, $2, $3)
+               returning id, archived, created_at, updated_at`
+	return r.pool.QueryRow(ctx, q, p.OwnerID, p.Name, p.Description).Scan(&p.ID, &p.Archived, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// GetByID mengambil project beserta anggotanya bila user adalah owner atau anggota.
+func (r *projectRepository) GetByID(ctx context.Context, userID, id string) (*Project, error) {
+	q := `select ` + projectColumns + ` from public.projects p where p.id=$1 and ` + projectVisibleTo(2)
+	p, err := scanProject(r.pool.QueryRow(ctx, q, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.Members, err = r.listMembers(ctx, p.ID)
+	return p, err
+}
+
+// IsArchived mengembalikan status arsip project tanpa memeriksa akses user;
+// project yang tidak ada dianggap tidak diarsipkan.
+func (r *projectRepository) IsArchived(ctx context.Context, id string) (bool, error) {
+	var archived bool
+	err := r.pool.QueryRow(ctx, `select archived from public.projects where id=$1`, id).Scan(&archived)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return archived, err
+}
+
+func (r *projectRepository) ListByUser(ctx context.Context, userID string, includeArchived bool) ([]Project, error) {
+	q := `select ` + projectColumns + ` from public.projects p where ` + projectVisibleTo(1)
+	if !includeArchived {
+		q += ` and not p.archived`
+	}
+	q += ` order by lower(p.name), p.id`
+	rows, err := r.pool.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var projects []Project
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *p)
+	}
+	return projects, rows.Err()
+}
+
+// Update mengubah project milik p.OwnerID.
+func (r *projectRepository) Update(ctx context.Context, p *Project) error {
+	const q = `update public.projects set name=$1, description=$2, archived=$3, updated_at=now()
+               where id=$4 and owner_id=$5 returning updated_at`
+	return r.pool.QueryRow(ctx, q, p.Name, p.Description, p.Archived, p.ID, p.OwnerID).Scan(&p.UpdatedAt)
+}
+
+func (r *projectRepository) Delete(ctx context.Context, ownerID, id string) error {
+	const q = `delete from public.projects where id=$1 and owner_id=$2`
+	tag, err := r.pool.Exec(ctx, q, id, ownerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// AddMember menambah anggota; menambah anggota yang sudah ada tidak error.
+func (r *projectRepository) AddMember(ctx context.Context, projectID, userID string) error {
+	const q = `insert into public.project_members (project_id, user_id) values ($1, $2) on conflict do nothing`
+	_, err := r.pool.Exec(ctx, q, projectID, userID)
+	return err
+}
+
+func (r *projectRepository) RemoveMember(ctx context.Context, projectID, userID string) error {
+	const q = `delete from public.project_members where project_id=$1 and user_id=$2`
+	tag, err := r.pool.Exec(ctx, q, projectID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *projectRepository) listMembers(ctx context.Context, projectID string) ([]ProjectMember, error) {
+	const q = `select u.id, u.name, u.email, m.added_at
+               from public.project_members m join public.users u on u.id = m.user_id
+               where m.project_id = $1 order by m.added_at`
+	rows, err := r.pool.Query(ctx, q, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	members := []ProjectMember{}
+	for rows.Next() {
+		var m ProjectMember
+		if err := rows.Scan(&m.UserID, &m.Name, &m.Email, &m.AddedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
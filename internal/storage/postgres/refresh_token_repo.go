@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RefreshToken adalah refresh token yang tersimpan dalam bentuk hash.
+// Token hasil rotasi berbagi FamilyID yang sama dengan token asalnya.
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	FamilyID  string
+	TokenHash string
+	// MFA menandai family yang dibuat lewat login dengan verifikasi MFA.
+	MFA       bool
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, t *RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	MarkUsed(ctx context.Context, id string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID string) error
+}
+
+type refreshTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRefreshTokenRepository(pool *pgxpool.Pool) RefreshTokenRepository {
+	return &refreshTokenRepository{pool: pool}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, t *RefreshToken) error {
+	const q = `insert into public.refresh_tokens (user_id, family_id, token_hash, mfa, expires_at)
+               values ($1, coalesce($2::uuid, gen_random_uuid()), $3, $4, $5)
+               returning id, family_id, created_at`
+	var familyID *string
+	if t.FamilyID != "" {
+		familyID = &t.FamilyID
+	}
+	return r.pool.QueryRow(ctx, q, t.UserID, familyID, t.TokenHash, t.MFA, t.ExpiresAt).
+		Scan(&t.ID, &t.FamilyID, &t.CreatedAt)
+}
+
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	const q = `select id, user_id, family_id, token_hash, mfa, expires_at, used_at, revoked_at, created_at
+               from public.refresh_tokens where token_hash=$1`
+	var t RefreshToken
+	if err := r.pool.QueryRow(ctx, q, tokenHash).Scan(
+		&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.MFA, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt, &t.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// MarkUsed menandai token sebagai sudah dirotasi. Mengembalikan false bila
+// token sudah dipakai atau dicabut lebih dulu (misalnya oleh request paralel).
+func (r *refreshTokenRepository) MarkUsed(ctx context.Context, id string) (bool, error) {
+	const q = `update public.refresh_tokens set used_at=now()
+               where id=$1 and used_at is null and revoked_at is null`
+	tag, err := r.pool.Exec(ctx, q, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	const q = `update public.refresh_tokens set revoked_at=now()
+               where family_id=$1 and revoked_at is null`
+	_, err := r.pool.Exec(ctx, q, familyID)
+	return err
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	const q = `update public.refresh_tokens set revoked_at=now()
+               where user_id=$1 and revoked_at is null`
+	_, err := r.pool.Exec(ctx, q, userID)
+	return err
+}
@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RevokedTokenRepository menyimpan denylist jti access token yang dicabut
+// sebelum kedaluwarsa. Entri yang sudah lewat expires_at tidak diperlukan lagi.
+type RevokedTokenRepository interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type revokedTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRevokedTokenRepository(pool *pgxpool.Pool) RevokedTokenRepository {
+	return &revokedTokenRepository{pool: pool}
+}
+
+func (r *revokedTokenRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	const q = `insert into public.revoked_tokens (jti, expires_at) values ($1, $2)
+               on conflict (jti) do nothing`
+	if _, err := r.pool.Exec(ctx, q, jti, expiresAt); err != nil {
+		return err
+	}
+	// bersihkan entri kedaluwarsa agar tabel tetap kecil
+	_, err := r.pool.Exec(ctx, `delete from public.revoked_tokens where expires_at < now()`)
+	return err
+}
+
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	const q = `select exists(select 1 from public.revoked_tokens where jti=$1)`
+	var revoked bool
+	err := r.pool.QueryRow(ctx, q, jti).Scan(&revoked)
+	return revoked, err
+}
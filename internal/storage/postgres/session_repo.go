@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Session adalah satu login (perangkat/browser). ID session sekaligus menjadi
+// family_id refresh token sehingga mencabut session berarti mencabut family-nya.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, s *Session) error
+	GetByID(ctx context.Context, id string) (*Session, error)
+	ListActiveByUser(ctx context.Context, userID string) ([]Session, error)
+	Extend(ctx context.Context, id string, expiresAt time.Time) error
+	Touch(ctx context.Context, id string) error
+	Revoke(ctx context.Context, userID, id string) error
+	RevokeAllForUser(ctx context.Context, userID string) error
+}
+
+type sessionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSessionRepository(pool *pgxpool.Pool) SessionRepository {
+	return &sessionRepository{pool: pool}
+}
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at`
+
+func scanSession(row pgx.Row) (*Session, error) {
+	var s Session
+	if err := row.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt, &s.RevokedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *sessionRepository) Create(ctx context.Context, s *Session) error {
+	const q = `insert into public.sessions (user_id, user_agent, ip, expires_at)
+               values ($1, $2, $3, $4)
+               returning id, created_at, last_seen_at`
+	return r.pool.QueryRow(ctx, q, s.UserID, s.UserAgent, s.IP, s.ExpiresAt).Scan(&s.ID, &s.CreatedAt, &s.LastSeenAt)
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id string) (*Session, error) {
+	q := `select ` + sessionColumns + ` from public.sessions where id=$1`
+	s, err := scanSession(r.pool.QueryRow(ctx, q, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return s, err
+}
+
+func (r *sessionRepository) ListActiveByUser(ctx context.Context, userID string) ([]Session, error) {
+	q := `select ` + sessionColumns + ` from public.sessions
+          where user_id=$1 and revoked_at is null and expires_at > now()
+          order by last_seen_at desc`
+	rows, err := r.pool.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sessions []Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, rows.Err()
+}
+
+// Extend dipanggil saat refresh token dirotasi.
+func (r *sessionRepository) Extend(ctx context.Context, id string, expiresAt time.Time) error {
+	const q = `update public.sessions set last_seen_at=now(), expires_at=$2 where id=$1`
+	_, err := r.pool.Exec(ctx, q, id, expiresAt)
+	return err
+}
+
+// Touch memperbarui last_seen_at, paling sering sekali per menit per session.
+func (r *sessionRepository) Touch(ctx context.Context, id string) error {
+	const q = `update public.sessions set last_seen_at=now()
+               where id=$1 and last_seen_at < now() - interval '1 minute'`
+	_, err := r.pool.Exec(ctx, q, id)
+	return err
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, userID, id string) error {
+	const q = `update public.sessions set revoked_at=now()
+               where id=$1 and user_id=$2 and revoked_at is null`
+	tag, err := r.pool.Exec(ctx, q, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *sessionRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	const q = `update public.sessions set revoked_at=now()
+               where user_id=$1 and revoked_at is null`
+	_, err := r.pool.Exec(ctx, q, userID)
+	return err
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidNeighbor dikembalikan Move bila task tetangga tidak ditemukan di
+// kolom tujuan atau urutannya terbalik.
+var ErrInvalidNeighbor = errors.New("task tetangga tidak valid untuk kolom tujuan")
+
+// BoardColumn adalah satu kolom kanban: task dengan status yang sama diurutkan
+// berdasarkan position. Total adalah jumlah task di kolom sebelum dibatasi limit.
+type BoardColumn struct {
+	Status TaskStatus `json:"status"`
+	Tasks  []Task     `json:"tasks"`
+	Total  int        `json:"total"`
+}
+
+// endPosition mengembalikan posisi setelah task terakhir di kolom status.
+func (r *taskRepository) endPosition(ctx context.Context, status TaskStatus) (string, error) {
+	var last *string
+	const q = `select max(position) from public.tasks where status=$1`
+	if err := r.pool.QueryRow(ctx, q, status).Scan(&last); err != nil {
+		return "", err
+	}
+	if last == nil {
+		return rankBetween("", "")
+	}
+	return rankAfter(*last), nil
+}
+
+// Board mengembalikan kolom kanban untuk setiap status (atau hanya f.Statuses)
+// berisi task yang terlihat oleh user, maksimal limit task per kolom.
+func (r *taskRepository) Board(ctx context.Context, userID string, f TaskFilter, limit int) ([]BoardColumn, error) {
+	statuses := TaskStatuses
+	if len(f.Statuses) > 0 {
+		statuses = nil
+		for _, s := range TaskStatuses {
+			for _, want := range f.Statuses {
+				if string(s) == want {
+					statuses = append(statuses, s)
+				}
+			}
+		}
+	}
+	conds, args := f.where([]any{userID})
+	where := append([]string{f.viewCondition(1)}, conds...)
+	args = append(args, limit)
+	q := fmt.Sprintf(`select %s, total from (
+            select *, row_number() over w as n, count(*) over (partition by status) as total
+            from public.tasks%s
+            window w as (partition by status order by position, id)
+          ) tasks where n <= $%d order by position, id`,
+		taskColumns, whereClause(where), len(args))
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make([]BoardColumn, len(statuses))
+	index := make(map[TaskStatus]int, len(statuses))
+	for i, s := range statuses {
+		columns[i] = BoardColumn{Status: s, Tasks: []Task{}}
+		index[s] = i
+	}
+	for rows.Next() {
+		var t Task
+		var total int
+		if err := rows.Scan(append(taskScanDest(&t), &total)...); err != nil {
+			return nil, err
+		}
+		if i, ok := index[t.Status]; ok {
+			columns[i].Tasks = append(columns[i].Tasks, t)
+			columns[i].Total = total
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var ptrs []*Task
+	for i := range columns {
+		for j := range columns[i].Tasks {
+			ptrs = append(ptrs, &columns[i].Tasks[j])
+		}
+	}
+	if err := r.loadDetails(ctx, ptrs...); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// Move memindahkan task t ke kolom status to di antara prevID (task di atasnya)
+// dan nextID (task di bawahnya); keduanya boleh kosong. Tanpa tetangga task
+// diletakkan di akhir kolom. Tetangga harus terlihat oleh viewerID dan berada di
+// kolom tujuan. Seperti Transition, mengembalikan pgx.ErrNoRows bila status task
+// sudah berubah sejak dibaca.
+func (r *taskRepository) Move(ctx context.Context, viewerID string, t *Task, to TaskStatus, prevID, nextID string) (*Task, error) {
+	var pos string
+	var err error
+	if prevID == "" && nextID == "" {
+		pos, err = r.endPosition(ctx, to)
+	} else {
+		var prev, next string
+		if prev, err = r.neighborPosition(ctx, viewerID, prevID, to); err == nil {
+			if next, err = r.neighborPosition(ctx, viewerID, nextID, to); err == nil {
+				pos, err = rankBetween(prev, next)
+			}
+		}
+		if errors.Is(err, errInvalidRankRange) {
+			err = ErrInvalidNeighbor
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	q := `update public.tasks set status=$4, position=$5,
+          completed_at=case when $4 = 'Done' then coalesce(completed_at, now()) end, updated_at=now()
+          where id=$1 and user_id=$2 and status=$3
+          returning ` + taskColumns
+	moved, err := scanTask(r.pool.QueryRow(ctx, q, t.ID, t.UserID, t.Status, to, pos))
+	if err != nil {
+		return nil, err
+	}
+	return moved, r.loadDetails(ctx, moved)
+}
+
+// neighborPosition mengambil posisi task tetangga; id kosong berarti tidak ada tetangga.
+func (r *taskRepository) neighborPosition(ctx context.Context, viewerID, id string, status TaskStatus) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+	var pos string
+	q := `select position from public.tasks where id=$1 and status=$2 and ` + visibleTo(3)
+	err := r.pool.QueryRow(ctx, q, id, status, viewerID).Scan(&pos)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrInvalidNeighbor
+	}
+	return pos, err
+}
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrDependencyCycle = errors.New("dependency membentuk siklus")
+
+// DependencyEdge berarti TaskID diblokir oleh DependsOnID.
+type DependencyEdge struct {
+	TaskID      string `json:"task_id"`
+	DependsOnID string `json:"depends_on_id"`
+}
+
+// DependencyGraph adalah task dalam satu project beserta dependency di antaranya.
+type DependencyGraph struct {
+	Nodes []TaskRef        `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+}
+
+// AddDependency menandai taskID diblokir oleh dependsOnID. Mengembalikan
+// ErrDependencyCycle bila dependsOnID (langsung atau tidak) sudah bergantung pada
+// taskID; menambah dependency yang sudah ada tidak error.
+func (r *taskRepository) AddDependency(ctx context.Context, taskID, dependsOnID, createdBy string) error {
+	if taskID == dependsOnID {
+		return ErrDependencyCycle
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	// serialisasi penambahan dependency agar dua penambahan paralel tidak
+	// sama-sama lolos pengecekan lalu membentuk siklus
+	if _, err := tx.Exec(ctx, `select pg_advisory_xact_lock(hashtext('task_dependencies'))`); err != nil {
+		return err
+	}
+	// ambil semua dependency yang dapat dicapai dari dependsOnID; pengecekan
+	// siklusnya dilakukan di dependencyCycle
+	const q = `with recursive reach (task_id, depends_on_id) as (
+                 select task_id, depends_on_id from public.task_dependencies where task_id = $1
+                 union
+                 select d.task_id, d.depends_on_id from public.task_dependencies d join reach r on d.task_id = r.depends_on_id
+               )
+               select task_id, depends_on_id from reach`
+	rows, err := tx.Query(ctx, q, dependsOnID)
+	if err != nil {
+		return err
+	}
+	var edges []DependencyEdge
+	for rows.Next() {
+		var e DependencyEdge
+		if err := rows.Scan(&e.TaskID, &e.DependsOnID); err != nil {
+			rows.Close()
+			return err
+		}
+		edges = append(edges, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if dependencyCycle(taskID, dependsOnID, edges) {
+		return ErrDependencyCycle
+	}
+	const ins = `insert into public.task_dependencies (task_id, depends_on_id, created_by) values ($1, $2, $3)
+                 on conflict do nothing`
+	if _, err := tx.Exec(ctx, ins, taskID, dependsOnID, createdBy); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// dependencyCycle melaporkan apakah edge taskID -> dependsOnID membentuk siklus,
+// yaitu bila taskID dapat dicapai dari dependsOnID lewat edges (termasuk
+// dependency ke diri sendiri).
+func dependencyCycle(taskID, dependsOnID string, edges []DependencyEdge) bool {
+	next := map[string][]string{}
+	for _, e := range edges {
+		next[e.TaskID] = append(next[e.TaskID], e.DependsOnID)
+	}
+	seen := map[string]bool{dependsOnID: true}
+	queue := []string{dependsOnID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == taskID {
+			return true
+		}
+		for _, n := range next[id] {
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return false
+}
+
+func (r *taskRepository) RemoveDependency(ctx context.Context, taskID, dependsOnID string) error {
+	const q = `delete from public.task_dependencies where task_id=$1 and depends_on_id=$2`
+	tag, err := r.pool.Exec(ctx, q, taskID, dependsOnID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// OpenBlockers mengembalikan task yang memblokir taskID dan belum Done/Cancelled
+// yang terlihat oleh viewerID (kosong = semua, untuk Admin), serta jumlah
+// pemblokir belum selesai lain yang tidak terlihat olehnya.
+func (r *taskRepository) OpenBlockers(ctx context.Context, taskID, viewerID string) ([]TaskRef, int, error) {
+	const open = `from public.task_dependencies d join public.tasks on tasks.id = d.depends_on_id
+                  where d.task_id = $1 and tasks.status not in ('Done', 'Cancelled')`
+	if viewerID == "" {
+		refs, err := r.listTaskRefs(ctx, `select tasks.id, tasks.title, tasks.status `+open+` order by tasks.created_at, tasks.id`, taskID)
+		return refs, 0, err
+	}
+	refs, err := r.listTaskRefs(ctx, `select tasks.id, tasks.title, tasks.status `+open+
+		` and `+visibleTo(2)+` order by tasks.created_at, tasks.id`, taskID, viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	var hidden int
+	err = r.pool.QueryRow(ctx, `select count(*) `+open+` and not `+visibleTo(2), taskID, viewerID).Scan(&hidden)
+	return refs, hidden, err
+}
+
+// DependencyGraph mengembalikan semua task dalam project sebagai node dan
+// dependency yang kedua ujungnya berada di project tersebut sebagai edge.
+func (r *taskRepository) DependencyGraph(ctx context.Context, projectID string) (*DependencyGraph, error) {
+	const qn = `select id, title, status from public.tasks where project_id = $1 order by position, id`
+	nodes, err := r.listTaskRefs(ctx, qn, projectID)
+	if err != nil {
+		return nil, err
+	}
+	const qe = `select d.task_id, d.depends_on_id
+                from public.task_dependencies d
+                join public.tasks t on t.id = d.task_id
+                join public.tasks b on b.id = d.depends_on_id
+                where t.project_id = $1 and b.project_id = $1
+                order by d.created_at`
+	rows, err := r.pool.Query(ctx, qe, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	graph := &DependencyGraph{Nodes: nodes, Edges: []DependencyEdge{}}
+	for rows.Next() {
+		var e DependencyEdge
+		if err := rows.Scan(&e.TaskID, &e.DependsOnID); err != nil {
+			return nil, err
+		}
+		graph.Edges = append(graph.Edges, e)
+	}
+	return graph, rows.Err()
+}
+
+// loadDependencies mengisi BlockedBy dan Blocks untuk detail task, hanya berisi task
+// yang terlihat oleh viewerID (kosong = semua, untuk Admin).
+func (r *taskRepository) loadDependencies(ctx context.Context, t *Task, viewerID string) error {
+	args := []any{t.ID}
+	visible := ""
+	if viewerID != "" {
+		visible = ` and ` + visibleTo(2)
+		args = append(args, viewerID)
+	}
+	var err error
+	qb := `select tasks.id, tasks.title, tasks.status
+           from public.task_dependencies d join public.tasks on tasks.id = d.depends_on_id
+           where d.task_id = $1` + visible + ` order by d.created_at`
+	if t.BlockedBy, err = r.listTaskRefs(ctx, qb, args...); err != nil {
+		return err
+	}
+	qf := `select tasks.id, tasks.title, tasks.status
+           from public.task_dependencies d join public.tasks on tasks.id = d.task_id
+           where d.depends_on_id = $1` + visible + ` order by d.created_at`
+	t.Blocks, err = r.listTaskRefs(ctx, qf, args...)
+	return err
+}
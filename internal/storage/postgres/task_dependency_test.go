@@ -0,0 +1,39 @@
+package postgres
+
+import "testing"
+
+func TestDependencyCycle(t *testing.T) {
+	// a diblokir b, b diblokir c, d diblokir c
+	edges := []DependencyEdge{{"a", "b"}, {"b", "c"}, {"d", "c"}}
+	tests := []struct {
+		name            string
+		task, dependsOn string
+		edges           []DependencyEdge
+		want            bool
+	}{
+		{"diri sendiri", "a", "a", nil, true},
+		{"diri sendiri dengan edge lain", "b", "b", edges, true},
+		{"siklus langsung", "b", "a", edges, true},
+		{"siklus transitif", "c", "a", edges, true},
+		{"cabang lain tanpa siklus", "a", "d", edges, false},
+		{"searah tidak siklus", "a", "c", edges, false},
+		{"task baru", "e", "a", edges, false},
+		{"tanpa edge", "a", "b", nil, false},
+	}
+	for _, tt := range tests {
+		if got := dependencyCycle(tt.task, tt.dependsOn, tt.edges); got != tt.want {
+			t.Errorf("%s: dependencyCycle(%q, %q) = %v, want %v", tt.name, tt.task, tt.dependsOn, got, tt.want)
+		}
+	}
+}
+
+func TestDependencyCycleTerminatesOnExistingLoop(t *testing.T) {
+	// data lama yang sudah bersiklus tidak boleh membuat pengecekan berputar selamanya
+	edges := []DependencyEdge{{"a", "b"}, {"b", "a"}}
+	if dependencyCycle("c", "a", edges) {
+		t.Fatal("c tidak terjangkau dari a")
+	}
+	if !dependencyCycle("b", "a", edges) {
+		t.Fatal("b terjangkau dari a")
+	}
+}
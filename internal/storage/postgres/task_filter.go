@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TaskFilter adalah filter dan urutan list task. Field kosong/nil berarti tanpa filter.
+// Rentang waktu bersifat inklusif.
+type TaskFilter struct {
+	// View membatasi list milik user: semua yang terlihat, yang di-assign, atau yang dibuat.
+	View TaskView
+	// ProjectID: hanya task dalam project ini.
+	ProjectID  string
+	Statuses   []string
+	Priorities []string
+	// LabelIDs: task yang memiliki minimal salah satu label.
+	LabelIDs    []string
+	DueFrom     *time.Time
+	DueTo       *time.Time
+	Overdue     bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	// Query dicari (ILIKE) pada title dan description.
+	Query string
+	// Sort salah satu TaskSortFields; kosong berarti created_at.
+	Sort string
+	Desc bool
+}
+
+type TaskView string
+
+const (
+	TaskViewAll      TaskView = ""
+	TaskViewAssigned TaskView = "assigned"
+	TaskViewCreated  TaskView = "created"
+)
+
+// visibleTo adalah kondisi task yang boleh dilihat user ($n): pembuat, assignee,
+// atau owner/anggota project tempat task berada.
+func visibleTo(n int) string {
+	return fmt.Sprintf(`(user_id = $%[1]d
+  or exists (select 1 from public.task_assignees a where a.task_id = tasks.id and a.user_id = $%[1]d)
+  or project_id in (select id from public.projects where owner_id = $%[1]d
+                    union all select project_id from public.project_members where user_id = $%[1]d))`, n)
+}
+
+// viewCondition adalah kondisi kepemilikan list task user ($n) sesuai View.
+func (f TaskFilter) viewCondition(n int) string {
+	switch f.View {
+	case TaskViewCreated:
+		return fmt.Sprintf("user_id = $%d", n)
+	case TaskViewAssigned:
+		return fmt.Sprintf("exists (select 1 from public.task_assignees a where a.task_id = tasks.id and a.user_id = $%d)", n)
+	}
+	return visibleTo(n)
+}
+
+// TaskSortFields adalah kolom yang boleh dipakai untuk mengurutkan task.
+var TaskSortFields = map[string]taskSortField{
+	"created_at": {expr: "created_at", typ: "timestamptz"},
+	"updated_at": {expr: "updated_at", typ: "timestamptz"},
+	"due_date":   {expr: "due_date", typ: "timestamptz"},
+	"title":      {expr: "lower(title)", typ: "text"},
+	"priority":   {expr: "array_position(array['Low', 'Medium', 'High', 'Urgent'], priority)", typ: "int"},
+	"position":   {expr: "position", typ: "text"},
+}
+
+type taskSortField struct {
+	expr string
+	typ  string
+}
+
+func (f TaskFilter) sortField() (string, taskSortField) {
+	if field, ok := TaskSortFields[f.Sort]; ok {
+		return f.Sort, field
+	}
+	return "created_at", TaskSortFields["created_at"]
+}
+
+// where menambahkan kondisi filter ke args dan mengembalikan potongan SQL-nya.
+func (f TaskFilter) where(args []any) ([]string, []any) {
+	var where []string
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ProjectID != "" {
+		add("project_id = $%d", f.ProjectID)
+	}
+	if len(f.Statuses) > 0 {
+		add("status = any($%d)", f.Statuses)
+	}
+	if len(f.Priorities) > 0 {
+		add("priority = any($%d)", f.Priorities)
+	}
+	if len(f.LabelIDs) > 0 {
+		add("exists (select 1 from public.task_labels tl where tl.task_id = tasks.id and tl.label_id = any($%d::uuid[]))", f.LabelIDs)
+	}
+	if f.DueFrom != nil {
+		add("due_date >= $%d", *f.DueFrom)
+	}
+	if f.DueTo != nil {
+		add("due_date <= $%d", *f.DueTo)
+	}
+	if f.Overdue {
+		where = append(where, "due_date < now() and status not in ('Done', 'Cancelled')")
+	}
+	if f.CreatedFrom != nil {
+		add("created_at >= $%d", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		add("created_at <= $%d", *f.CreatedTo)
+	}
+	if f.UpdatedFrom != nil {
+		add("updated_at >= $%d", *f.UpdatedFrom)
+	}
+	if f.UpdatedTo != nil {
+		add("updated_at <= $%d", *f.UpdatedTo)
+	}
+	if f.Query != "" {
+		args = append(args, "%"+escapeLike(f.Query)+"%")
+		where = append(where, fmt.Sprintf("(title ilike $%d or description ilike $%d)", len(args), len(args)))
+	}
+	return where, args
+}
+
+// orderBy mengembalikan klausa ORDER BY; id dipakai sebagai tie-breaker agar urutan
+// stabil untuk keyset pagination. backward membalik urutan untuk mengambil halaman sebelumnya.
+func (f TaskFilter) orderBy(backward bool) string {
+	_, field := f.sortField()
+	desc := f.Desc != backward
+	dir, nulls := "asc", "last"
+	if desc {
+		dir = "desc"
+	}
+	if backward {
+		nulls = "first"
+	}
+	return fmt.Sprintf(" order by %s %s nulls %s, id %s", field.expr, dir, nulls, dir)
+}
+
+// after menambahkan kondisi keyset "setelah cursor" (atau "sebelum" bila cursor
+// mundur) sesuai urutan filter. NULL selalu berada di akhir urutan.
+func (f TaskFilter) after(c *cursor, args []any) (string, []any) {
+	_, field := f.sortField()
+	// maju pada urutan naik (atau mundur pada urutan turun) berarti nilai lebih besar
+	op := "<"
+	if f.Desc == c.Backward {
+		op = ">"
+	}
+	args = append(args, c.ID)
+	id := len(args)
+	if c.Value == nil {
+		if c.Backward {
+			return fmt.Sprintf("(%s is not null or id %s $%d)", field.expr, op, id), args
+		}
+		return fmt.Sprintf("(%s is null and id %s $%d)", field.expr, op, id), args
+	}
+	args = append(args, *c.Value)
+	v := fmt.Sprintf("$%d::%s", len(args), field.typ)
+	cmp := fmt.Sprintf("(%[1]s %[2]s %[3]s or (%[1]s = %[3]s and id %[2]s $%[4]d))", field.expr, op, v, id)
+	if c.Backward {
+		return fmt.Sprintf("(%s is not null and %s)", field.expr, cmp), args
+	}
+	return fmt.Sprintf("(%s or %s is null)", cmp, field.expr), args
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestTaskFilterOrderBy(t *testing.T) {
+	tests := []struct {
+		desc, backward bool
+		want           string
+	}{
+		{false, false, " order by created_at asc nulls last, id asc"},
+		{false, true, " order by created_at desc nulls first, id desc"},
+		{true, false, " order by created_at desc nulls last, id desc"},
+		{true, true, " order by created_at asc nulls first, id asc"},
+	}
+	for _, tt := range tests {
+		if got := (TaskFilter{Desc: tt.desc}).orderBy(tt.backward); got != tt.want {
+			t.Errorf("orderBy(desc=%v, backward=%v) = %q, want %q", tt.desc, tt.backward, got, tt.want)
+		}
+	}
+}
+
+func TestTaskFilterAfter(t *testing.T) {
+	v := "2024-05-01T10:00:00Z"
+	tests := []struct {
+		name string
+		desc bool
+		cur  cursor
+		want string
+	}{
+		{"maju naik", false, cursor{Value: &v, ID: "x"},
+			"((created_at > $2::timestamptz or (created_at = $2::timestamptz and id > $1)) or created_at is null)"},
+		{"maju turun", true, cursor{Value: &v, ID: "x"},
+			"((created_at < $2::timestamptz or (created_at = $2::timestamptz and id < $1)) or created_at is null)"},
+		{"mundur naik", false, cursor{Value: &v, ID: "x", Backward: true},
+			"(created_at is not null and (created_at < $2::timestamptz or (created_at = $2::timestamptz and id < $1)))"},
+		{"mundur turun", true, cursor{Value: &v, ID: "x", Backward: true},
+			"(created_at is not null and (created_at > $2::timestamptz or (created_at = $2::timestamptz and id > $1)))"},
+		{"maju dari null", false, cursor{ID: "x"}, "(created_at is null and id > $1)"},
+		{"mundur dari null", false, cursor{ID: "x", Backward: true}, "(created_at is not null or id < $1)"},
+	}
+	for _, tt := range tests {
+		got, args := TaskFilter{Desc: tt.desc}.after(&tt.cur, nil)
+		if got != tt.want {
+			t.Errorf("%s: after = %q, want %q", tt.name, got, tt.want)
+		}
+		want := []any{"x"}
+		if tt.cur.Value != nil {
+			want = append(want, v)
+		}
+		if !reflect.DeepEqual(args, want) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, want)
+		}
+	}
+}
+
+type pageRow struct {
+	key *string
+	id  string
+}
+
+// pageRows membuat baris dengan nilai sort berulang dan beberapa NULL agar
+// tie-breaker id dan posisi NULL ikut teruji.
+func pageRows() []pageRow {
+	var rows []pageRow
+	for i := 0; i < 23; i++ {
+		var key *string
+		if i%5 != 4 {
+			k := fmt.Sprintf("%02d", i/3)
+			key = &k
+		}
+		rows = append(rows, pageRow{key: key, id: fmt.Sprintf("id-%02d", i)})
+	}
+	return rows
+}
+
+// less adalah urutan tampil list task: nilai sort (NULL selalu di akhir) lalu id,
+// keduanya dibalik bila desc. Sama dengan orderBy(false) dan predikat after.
+func less(a, b pageRow, desc bool) bool {
+	switch {
+	case a.key == nil && b.key == nil:
+	case a.key == nil:
+		return false
+	case b.key == nil:
+		return true
+	case *a.key != *b.key:
+		return (*a.key < *b.key) != desc
+	}
+	return a.id != b.id && (a.id < b.id) != desc
+}
+
+// fetchPage mensimulasikan query list: ambil limit+1 baris setelah/sebelum cursor
+// dalam urutan query, potong, balik bila mundur, lalu isi cursor halaman.
+func fetchPage(t *testing.T, rows []pageRow, f TaskFilter, token string, limit int) ([]string, *PageInfo) {
+	t.Helper()
+	var cur *cursor
+	if token != "" {
+		c, err := decodeCursor(token)
+		if err != nil {
+			t.Fatalf("decodeCursor: %v", err)
+		}
+		cur = c
+	}
+	sorted := slices.Clone(rows)
+	slices.SortFunc(sorted, func(a, b pageRow) int {
+		if less(a, b, f.Desc) {
+			return -1
+		}
+		return 1
+	})
+	var candidates []pageRow
+	for _, r := range sorted {
+		switch {
+		case cur == nil:
+			candidates = append(candidates, r)
+		case cur.Backward && less(r, pageRow{key: cur.Value, id: cur.ID}, f.Desc):
+			candidates = append(candidates, r)
+		case !cur.Backward && less(pageRow{key: cur.Value, id: cur.ID}, r, f.Desc):
+			candidates = append(candidates, r)
+		}
+	}
+	backward := cur != nil && cur.Backward
+	if backward {
+		slices.Reverse(candidates)
+	}
+	more := len(candidates) > limit
+	if more {
+		candidates = candidates[:limit]
+	}
+	if backward {
+		slices.Reverse(candidates)
+	}
+	ids := make([]string, len(candidates))
+	keys := make([]*string, len(candidates))
+	for i, r := range candidates {
+		ids[i], keys[i] = r.id, r.key
+	}
+	info := &PageInfo{Limit: limit}
+	f.setPageCursors(info, cur, ids, keys, more)
+	return ids, info
+}
+
+func TestKeysetPaging(t *testing.T) {
+	rows := pageRows()
+	for _, desc := range []bool{false, true} {
+		for _, limit := range []int{1, 4, 5, 23, 50} {
+			t.Run(fmt.Sprintf("desc=%v/limit=%d", desc, limit), func(t *testing.T) {
+				f := TaskFilter{Sort: "due_date", Desc: desc}
+				var want []string
+				all, _ := fetchPage(t, rows, f, "", len(rows))
+				want = append(want, all...)
+
+				// maju dari halaman pertama sampai NextCursor kosong
+				var pages [][]string
+				var prevs []string
+				token := ""
+				for {
+					ids, info := fetchPage(t, rows, f, token, limit)
+					if token == "" && info.PrevCursor != "" {
+						t.Fatal("halaman pertama punya PrevCursor")
+					}
+					if token != "" && info.PrevCursor == "" {
+						t.Fatal("halaman lanjutan tanpa PrevCursor")
+					}
+					pages = append(pages, ids)
+					prevs = append(prevs, info.PrevCursor)
+					if info.NextCursor == "" {
+						break
+					}
+					token = info.NextCursor
+				}
+				if got := slices.Concat(pages...); !slices.Equal(got, want) {
+					t.Fatalf("maju = %v, want %v", got, want)
+				}
+
+				// mundur dari halaman terakhir harus menghasilkan halaman yang sama
+				for i := len(pages) - 1; i > 0; i-- {
+					ids, info := fetchPage(t, rows, f, prevs[i], limit)
+					if !slices.Equal(ids, pages[i-1]) {
+						t.Fatalf("mundur ke halaman %d = %v, want %v", i-1, ids, pages[i-1])
+					}
+					if info.NextCursor == "" {
+						t.Fatalf("halaman %d hasil mundur tanpa NextCursor", i-1)
+					}
+					if (i-1 == 0) != (info.PrevCursor == "") {
+						t.Fatalf("halaman %d hasil mundur: PrevCursor = %q", i-1, info.PrevCursor)
+					}
+					next, _ := fetchPage(t, rows, f, info.NextCursor, limit)
+					if !slices.Equal(next, pages[i]) {
+						t.Fatalf("maju lagi ke halaman %d = %v, want %v", i, next, pages[i])
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package postgres
+
+import "slices"
+
+type TaskPriority string
+
+const (
+	TaskPriorityLow    TaskPriority = "Low"
+	TaskPriorityMedium TaskPriority = "Medium"
+	TaskPriorityHigh   TaskPriority = "High"
+	TaskPriorityUrgent TaskPriority = "Urgent"
+)
+
+// TaskPriorities berurutan dari yang paling rendah; urutan ini dipakai saat sort.
+var TaskPriorities = []TaskPriority{TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh, TaskPriorityUrgent}
+
+func (p TaskPriority) Valid() bool {
+	return slices.Contains(TaskPriorities, p)
+}
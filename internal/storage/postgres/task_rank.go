@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"errors"
+	"strings"
+)
+
+// rankDigits adalah alfabet posisi task; urutannya sama dengan collation "C"
+// kolom tasks.position sehingga perbandingan string = urutan di board.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+var errInvalidRankRange = errors.New("posisi tetangga tidak berurutan")
+
+// rankBetween mengembalikan posisi yang berada di antara prev dan next
+// (keduanya eksklusif). prev kosong berarti awal kolom, next kosong berarti
+// akhir kolom. Hanya satu baris yang perlu diubah per perpindahan; panjang
+// posisi bertambah bila terus disisipkan di celah yang sama.
+func rankBetween(prev, next string) (string, error) {
+	if next != "" && prev >= next {
+		return "", errInvalidRankRange
+	}
+	var b strings.Builder
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(prev) {
+			lo = strings.IndexByte(rankDigits, prev[i])
+		}
+		hi := len(rankDigits)
+		if next != "" {
+			if i >= len(next) {
+				// next adalah prefix dari prev, tidak mungkin bila prev < next
+				return "", errInvalidRankRange
+			}
+			hi = strings.IndexByte(rankDigits, next[i])
+		}
+		if lo < 0 || hi < 0 {
+			return "", errInvalidRankRange
+		}
+		if lo == hi {
+			b.WriteByte(rankDigits[lo])
+			continue
+		}
+		// digit tengah tidak pernah 0 sehingga posisi baru tidak diakhiri digit terkecil
+		if mid := (lo + hi) / 2; mid > lo {
+			b.WriteByte(rankDigits[mid])
+			return b.String(), nil
+		}
+		// tidak ada digit di antara lo dan hi: ambil lo lalu cari di digit berikutnya
+		// tanpa batas atas karena prefix-nya sudah lebih kecil dari next
+		b.WriteByte(rankDigits[lo])
+		next = ""
+	}
+}
+
+// rankAfter mengembalikan posisi setelah prev untuk menambah task di akhir kolom.
+// Digit paling kiri yang masih bisa dinaikkan dipakai agar posisi tetap pendek
+// walau task terus ditambahkan di akhir.
+func rankAfter(prev string) string {
+	for i := 0; i < len(prev); i++ {
+		if d := strings.IndexByte(rankDigits, prev[i]); d >= 0 && d < len(rankDigits)-1 {
+			return prev[:i] + string(rankDigits[d+1])
+		}
+	}
+	return prev + string(rankDigits[1])
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		prev, next string
+		want       string
+		err        error
+	}{
+		{"", "", "i", nil},
+		{"", "1", "0i", nil},
+		{"a", "", "n", nil},
+		{"a", "c", "b", nil},
+		{"a", "b", "ai", nil},
+		{"az", "b", "azi", nil},
+		{"a1", "a2", "a1i", nil},
+		{"b", "a", "", errInvalidRankRange},
+		{"a", "a", "", errInvalidRankRange},
+		{"a", "a0", "", errInvalidRankRange},
+		{"A", "", "", errInvalidRankRange},
+		{"!", "", "", errInvalidRankRange},
+	}
+	for _, tt := range tests {
+		got, err := rankBetween(tt.prev, tt.next)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("rankBetween(%q, %q) err = %v, want %v", tt.prev, tt.next, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("rankBetween(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+		}
+		if err == nil && (got <= tt.prev || (tt.next != "" && got >= tt.next)) {
+			t.Errorf("rankBetween(%q, %q) = %q di luar rentang", tt.prev, tt.next, got)
+		}
+	}
+}
+
+func TestRankBetweenRandomInserts(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ranks := []string{}
+	for i := 0; i < 2000; i++ {
+		at := rng.Intn(len(ranks) + 1)
+		var prev, next string
+		if at > 0 {
+			prev = ranks[at-1]
+		}
+		if at < len(ranks) {
+			next = ranks[at]
+		}
+		r, err := rankBetween(prev, next)
+		if err != nil {
+			t.Fatalf("insert %d: rankBetween(%q, %q): %v", i, prev, next, err)
+		}
+		if strings.HasSuffix(r, "0") {
+			t.Fatalf("insert %d: %q diakhiri digit terkecil", i, r)
+		}
+		ranks = slices.Insert(ranks, at, r)
+	}
+	for i := 1; i < len(ranks); i++ {
+		if ranks[i-1] >= ranks[i] {
+			t.Fatalf("urutan rusak di %d: %q >= %q", i, ranks[i-1], ranks[i])
+		}
+	}
+}
+
+func TestRankAfter(t *testing.T) {
+	tests := []struct {
+		prev, want string
+	}{
+		{"", "1"},
+		{"a", "b"},
+		{"a5", "b"},
+		{"z", "z1"},
+		{"zz5", "zz6"},
+		{"zzz", "zzz1"},
+	}
+	for _, tt := range tests {
+		if got := rankAfter(tt.prev); got != tt.want {
+			t.Errorf("rankAfter(%q) = %q, want %q", tt.prev, got, tt.want)
+		}
+	}
+}
+
+func TestRankAfterAppendsStayOrderedAndShort(t *testing.T) {
+	prev := ""
+	for i := 0; i < 1000; i++ {
+		r := rankAfter(prev)
+		if r <= prev {
+			t.Fatalf("append %d: rankAfter(%q) = %q tidak lebih besar", i, prev, r)
+		}
+		// posisi di akhir kolom harus tetap bisa disisipi dari depan
+		if _, err := rankBetween(prev, r); err != nil {
+			t.Fatalf("append %d: tidak ada celah antara %q dan %q: %v", i, prev, r, err)
+		}
+		prev = r
+	}
+	if len(prev) > 30 {
+		t.Errorf("posisi setelah 1000 append terlalu panjang: %d karakter", len(prev))
+	}
+}
@@ -2,30 +2,87 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Task struct {
-	ID          string     `json:"id"`
-	UserID      string     `json:"user_id"`
-	Title       string     `json:"title"`
-	Description *string    `json:"description,omitempty"`
-	Status      string     `json:"status"`
-	DueDate     *time.Time `json:"due_date,omitempty"`
+	ID          string       `json:"id"`
+	UserID      string       `json:"user_id"`
+	ProjectID   *string      `json:"project_id,omitempty"`
+	ParentID    *string      `json:"parent_id,omitempty"`
+	Title       string       `json:"title"`
+	Description *string      `json:"description,omitempty"`
+	Status      TaskStatus   `json:"status"`
+	Priority    TaskPriority `json:"priority"`
+	DueDate     *time.Time   `json:"due_date,omitempty"`
+	// Position adalah urutan task di kolom status-nya (board kanban).
+	Position string `json:"position"`
+	// CompletedAt diisi otomatis saat task masuk Done dan dikosongkan saat dibuka kembali.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+	// Labels dan Assignees diisi oleh GetByID, list dan search.
+	Labels    []Label        `json:"labels"`
+	Assignees []TaskAssignee `json:"assignees"`
+	// Subtasks, Checklist, dependency dan Progress (persen) hanya diisi oleh GetByID dan GetAnyByID.
+	Subtasks  []TaskRef       `json:"subtasks,omitempty"`
+	BlockedBy []TaskRef       `json:"blocked_by,omitempty"`
+	Blocks    []TaskRef       `json:"blocks,omitempty"`
+	Checklist []ChecklistItem `json:"checklist,omitempty"`
+	Progress  *int            `json:"progress,omitempty"`
+}
+
+// TaskAssignee adalah user yang ditugaskan mengerjakan task selain pembuatnya.
+type TaskAssignee struct {
+	UserID     string    `json:"user_id"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email"`
+	AssignedAt time.Time `json:"assigned_at"`
 }
 
 type TaskRepository interface {
 	Create(ctx context.Context, t *Task) error
 	GetByID(ctx context.Context, userID, id string) (*Task, error)
-	ListByUser(ctx context.Context, userID string, limit, offset int) ([]Task, error)
+	ListByUser(ctx context.Context, userID string, f TaskFilter, page PageRequest) ([]Task, *PageInfo, error)
+	ListByProject(ctx context.Context, userID, projectID string, f TaskFilter, page PageRequest) ([]Task, *PageInfo, error)
+	GetAnyByID(ctx context.Context, id string) (*Task, error)
+	ListAll(ctx context.Context, userID string, f TaskFilter, page PageRequest) ([]Task, *PageInfo, error)
+	Search(ctx context.Context, userID, query string, f TaskFilter, page PageRequest) ([]TaskSearchResult, *PageInfo, error)
 	Update(ctx context.Context, t *Task) error
+	CheckParent(ctx context.Context, id, parentID string) error
+	Transition(ctx context.Context, userID, id string, from, to TaskStatus) (*Task, error)
+	Move(ctx context.Context, viewerID string, t *Task, to TaskStatus, prevID, nextID string) (*Task, error)
+	Board(ctx context.Context, userID string, f TaskFilter, limit int) ([]BoardColumn, error)
+	AddAssignee(ctx context.Context, taskID, userID, assignedBy string) error
+	RemoveAssignee(ctx context.Context, taskID, userID string) error
+	AddDependency(ctx context.Context, taskID, dependsOnID, createdBy string) error
+	RemoveDependency(ctx context.Context, taskID, dependsOnID string) error
+	OpenBlockers(ctx context.Context, taskID, viewerID string) ([]TaskRef, int, error)
+	DependencyGraph(ctx context.Context, projectID string) (*DependencyGraph, error)
 	Delete(ctx context.Context, userID, id string) error
 }
 
+const taskColumns = `id, user_id, project_id, parent_id, title, description, status, priority, due_date, position, completed_at, created_at, updated_at`
+
+// taskScanDest mengembalikan tujuan Scan sesuai urutan taskColumns.
+func taskScanDest(t *Task) []any {
+	return []any{&t.ID, &t.UserID, &t.ProjectID, &t.ParentID, &t.Title, &t.Description, &t.Status, &t.Priority, &t.DueDate, &t.Position, &t.CompletedAt, &t.CreatedAt, &t.UpdatedAt}
+}
+
+func scanTask(row pgx.Row) (*Task, error) {
+	var t Task
+	if err := row.Scan(taskScanDest(&t)...); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 type taskRepository struct {
 	pool *pgxpool.Pool
 }
@@ -34,56 +91,303 @@ func NewTaskRepository(pool *pgxpool.Pool) TaskRepository {
 	return &taskRepository{pool: pool}
 }
 
+// Create menyimpan task baru di akhir kolom status-nya.
 func (r *taskRepository) Create(ctx context.Context, t *Task) error {
-	const q = `insert into public.tasks (user_id, title, description, status, due_date)
-               values ($1, $2, $3, $4, $5)
-               returning id, created_at, updated_at`
-	return r.pool.QueryRow(ctx, q, t.UserID, t.Title, t.Description, t.Status, t.DueDate).
-		Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	pos, err := r.endPosition(ctx, t.Status)
+	if err != nil {
+		return err
+	}
+	t.Position = pos
+	const q = `insert into public.tasks (user_id, title, description, status, priority, due_date, project_id, parent_id, position, completed_at)
+               values ($1, $2, $3, $4, $5, $6, $7, $8, $9, case when $4 = 'Done' then now() end)
+               returning id, completed_at, created_at, updated_at`
+	return r.pool.QueryRow(ctx, q, t.UserID, t.Title, t.Description, t.Status, t.Priority, t.DueDate, t.ProjectID, t.ParentID, t.Position).
+		Scan(&t.ID, &t.CompletedAt, &t.CreatedAt, &t.UpdatedAt)
 }
 
+// GetByID mengambil task yang terlihat oleh user (pembuat, assignee atau anggota project).
 func (r *taskRepository) GetByID(ctx context.Context, userID, id string) (*Task, error) {
-	const q = `select id, user_id, title, description, status, due_date, created_at, updated_at
-               from public.tasks where id=$1 and user_id=$2`
-	var t Task
-	if err := r.pool.QueryRow(ctx, q, id, userID).Scan(
-		&t.ID, &t.UserID, &t.Title, &t.Description, &t.Status, &t.DueDate, &t.CreatedAt, &t.UpdatedAt,
-	); err != nil {
+	q := `select ` + taskColumns + ` from public.tasks where id=$1 and ` + visibleTo(2)
+	t, err := scanTask(r.pool.QueryRow(ctx, q, id, userID))
+	if err != nil {
 		return nil, err
 	}
-	return &t, nil
+	if err := r.loadDetails(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, r.loadBreakdown(ctx, t, userID)
 }
 
-func (r *taskRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]Task, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 20
-	}
-	if offset < 0 {
-		offset = 0
+func (r *taskRepository) ListByUser(ctx context.Context, userID string, f TaskFilter, page PageRequest) ([]Task, *PageInfo, error) {
+	return r.list(ctx, []string{f.viewCondition(1)}, []any{userID}, f, page)
+}
+
+// ListByProject mengembalikan task dalam project; keanggotaan user dicek oleh pemanggil.
+// View selain semua tetap membatasi ke task yang dibuat/di-assign ke user.
+func (r *taskRepository) ListByProject(ctx context.Context, userID, projectID string, f TaskFilter, page PageRequest) ([]Task, *PageInfo, error) {
+	f.ProjectID = projectID
+	if f.View == TaskViewAll {
+		return r.list(ctx, nil, nil, f, page)
 	}
-	const q = `select id, user_id, title, description, status, due_date, created_at, updated_at
-               from public.tasks where user_id=$1 order by created_at desc limit $2 offset $3`
-	rows, err := r.pool.Query(ctx, q, userID, limit, offset)
+	return r.list(ctx, []string{f.viewCondition(1)}, []any{userID}, f, page)
+}
+
+// GetAnyByID mengambil task tanpa memeriksa pemiliknya (untuk Admin).
+func (r *taskRepository) GetAnyByID(ctx context.Context, id string) (*Task, error) {
+	q := `select ` + taskColumns + ` from public.tasks where id=$1`
+	t, err := scanTask(r.pool.QueryRow(ctx, q, id))
 	if err != nil {
 		return nil, err
 	}
+	if err := r.loadDetails(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, r.loadBreakdown(ctx, t, "")
+}
+
+// ListAll mengembalikan task semua user (untuk Admin); userID kosong berarti tanpa filter.
+func (r *taskRepository) ListAll(ctx context.Context, userID string, f TaskFilter, page PageRequest) ([]Task, *PageInfo, error) {
+	var where []string
+	var args []any
+	if userID != "" {
+		args = append(args, userID)
+		where = append(where, "user_id = $1")
+	}
+	return r.list(ctx, where, args, f, page)
+}
+
+// list menjalankan query list task dengan keyset pagination. Satu baris ekstra
+// diambil untuk mengetahui apakah masih ada halaman berikutnya.
+func (r *taskRepository) list(ctx context.Context, where []string, args []any, f TaskFilter, page PageRequest) ([]Task, *PageInfo, error) {
+	sortName, field := f.sortField()
+	limit := page.limit()
+	info := &PageInfo{Limit: limit}
+
+	conds, args := f.where(args)
+	where = append(where, conds...)
+	if page.WithTotal {
+		var total int
+		q := `select count(*) from public.tasks` + whereClause(where)
+		if err := r.pool.QueryRow(ctx, q, args...).Scan(&total); err != nil {
+			return nil, nil, err
+		}
+		info.Total = &total
+	}
+
+	var cur *cursor
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		if c.Sort != sortName || c.Desc != f.Desc {
+			return nil, nil, ErrInvalidCursor
+		}
+		cur = c
+		var cond string
+		cond, args = f.after(cur, args)
+		where = append(where, cond)
+	}
+	backward := cur != nil && cur.Backward
+
+	args = append(args, limit+1)
+	q := fmt.Sprintf(`select %s, (%s)::text from public.tasks%s%s limit $%d`,
+		taskColumns, field.expr, whereClause(where), f.orderBy(backward), len(args))
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, nil, err
+	}
 	defer rows.Close()
 	var tasks []Task
+	var keys []*string
 	for rows.Next() {
 		var t Task
-		if err := rows.Scan(&t.ID, &t.UserID, &t.Title, &t.Description, &t.Status, &t.DueDate, &t.CreatedAt, &t.UpdatedAt); err != nil {
-			return nil, err
+		var key *string
+		if err := rows.Scan(append(taskScanDest(&t), &key)...); err != nil {
+			return nil, nil, err
 		}
 		tasks = append(tasks, t)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	more := len(tasks) > limit
+	if more {
+		tasks, keys = tasks[:limit], keys[:limit]
+	}
+	if backward {
+		slices.Reverse(tasks)
+		slices.Reverse(keys)
 	}
-	return tasks, nil
+	ptrs := make([]*Task, len(tasks))
+	for i := range tasks {
+		ptrs[i] = &tasks[i]
+	}
+	if err := r.loadDetails(ctx, ptrs...); err != nil {
+		return nil, nil, err
+	}
+	ids := make([]string, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+	}
+	f.setPageCursors(info, cur, ids, keys, more)
+	return tasks, info, nil
 }
 
+// setPageCursors mengisi NextCursor/PrevCursor untuk halaman berisi ids (urutan
+// tampil) dengan nilai sort keys. more berarti query menemukan baris ekstra di
+// arah pengambilan. Maju: ada halaman berikutnya bila baris ekstra ada, halaman
+// sebelumnya bila datang dari cursor; mundur: kebalikannya.
+func (f TaskFilter) setPageCursors(info *PageInfo, cur *cursor, ids []string, keys []*string, more bool) {
+	if len(ids) == 0 {
+		return
+	}
+	sortName, _ := f.sortField()
+	backward := cur != nil && cur.Backward
+	at := func(i int, back bool) string {
+		return cursor{Sort: sortName, Desc: f.Desc, Value: keys[i], ID: ids[i], Backward: back}.encode()
+	}
+	if more || backward {
+		info.NextCursor = at(len(ids)-1, false)
+	}
+	if (backward && more) || (!backward && cur != nil) {
+		info.PrevCursor = at(0, true)
+	}
+}
+
+func whereClause(where []string) string {
+	if len(where) == 0 {
+		return ""
+	}
+	return " where " + strings.Join(where, " and ")
+}
+
+// Update menyimpan perubahan task; bila status berubah task pindah ke akhir kolom barunya.
 func (r *taskRepository) Update(ctx context.Context, t *Task) error {
-	const q = `update public.tasks set title=$1, description=$2, status=$3, due_date=$4, updated_at=now()
-               where id=$5 and user_id=$6 returning updated_at`
-	return r.pool.QueryRow(ctx, q, t.Title, t.Description, t.Status, t.DueDate, t.ID, t.UserID).
-		Scan(&t.UpdatedAt)
+	pos, err := r.endPosition(ctx, t.Status)
+	if err != nil {
+		return err
+	}
+	const q = `update public.tasks set title=$1, description=$2, status=$3, priority=$4, due_date=$5, project_id=$8,
+               parent_id=$10, position=case when status <> $3 then $9 else position end,
+               completed_at=case when $3 = 'Done' then coalesce(completed_at, now()) end, updated_at=now()
+               where id=$6 and user_id=$7 returning position, completed_at, updated_at`
+	return r.pool.QueryRow(ctx, q, t.Title, t.Description, t.Status, t.Priority, t.DueDate, t.ID, t.UserID, t.ProjectID, pos, t.ParentID).
+		Scan(&t.Position, &t.CompletedAt, &t.UpdatedAt)
+}
+
+// Transition memindahkan status task milik userID (pembuat) hanya bila statusnya masih from, sehingga
+// perubahan paralel tidak melompati aturan transisi. Mengembalikan pgx.ErrNoRows
+// bila task tidak ditemukan atau statusnya sudah berubah.
+func (r *taskRepository) Transition(ctx context.Context, userID, id string, from, to TaskStatus) (*Task, error) {
+	pos, err := r.endPosition(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	q := `update public.tasks set status=$4, position=case when status <> $4 then $5 else position end,
+          completed_at=case when $4 = 'Done' then coalesce(completed_at, now()) end, updated_at=now()
+          where id=$1 and user_id=$2 and status=$3
+          returning ` + taskColumns
+	t, err := scanTask(r.pool.QueryRow(ctx, q, id, userID, from, to, pos))
+	if err != nil {
+		return nil, err
+	}
+	return t, r.loadDetails(ctx, t)
+}
+
+func (r *taskRepository) AddAssignee(ctx context.Context, taskID, userID, assignedBy string) error {
+	const q = `insert into public.task_assignees (task_id, user_id, assigned_by) values ($1, $2, $3)
+               on conflict do nothing`
+	_, err := r.pool.Exec(ctx, q, taskID, userID, assignedBy)
+	return err
+}
+
+func (r *taskRepository) RemoveAssignee(ctx context.Context, taskID, userID string) error {
+	const q = `delete from public.task_assignees where task_id=$1 and user_id=$2`
+	tag, err := r.pool.Exec(ctx, q, taskID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// loadDetails mengisi relasi task (label, assignee).
+func (r *taskRepository) loadDetails(ctx context.Context, tasks ...*Task) error {
+	if err := r.loadLabels(ctx, tasks...); err != nil {
+		return err
+	}
+	return r.loadAssignees(ctx, tasks...)
+}
+
+// loadLabels mengisi Labels untuk tasks dengan satu query.
+func (r *taskRepository) loadLabels(ctx context.Context, tasks ...*Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	byID := make(map[string]*Task, len(tasks))
+	ids := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		t.Labels = []Label{}
+		byID[t.ID] = t
+		ids = append(ids, t.ID)
+	}
+	const q = `select tl.task_id, l.id, l.user_id, l.name, l.color, l.created_at
+               from public.task_labels tl join public.labels l on l.id = tl.label_id
+               where tl.task_id = any($1) order by lower(l.name)`
+	rows, err := r.pool.Query(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var taskID string
+		var l Label
+		if err := rows.Scan(&taskID, &l.ID, &l.UserID, &l.Name, &l.Color, &l.CreatedAt); err != nil {
+			return err
+		}
+		if t, ok := byID[taskID]; ok {
+			t.Labels = append(t.Labels, l)
+		}
+	}
+	return rows.Err()
+}
+
+// loadAssignees mengisi Assignees untuk tasks dengan satu query.
+func (r *taskRepository) loadAssignees(ctx context.Context, tasks ...*Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	byID := make(map[string]*Task, len(tasks))
+	ids := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		t.Assignees = []TaskAssignee{}
+		byID[t.ID] = t
+		ids = append(ids, t.ID)
+	}
+	const q = `select a.task_id, u.id, u.name, u.email, a.assigned_at
+               from public.task_assignees a join public.users u on u.id = a.user_id
+               where a.task_id = any($1) order by a.assigned_at`
+	rows, err := r.pool.Query(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var taskID string
+		var a TaskAssignee
+		if err := rows.Scan(&taskID, &a.UserID, &a.Name, &a.Email, &a.AssignedAt); err != nil {
+			return err
+		}
+		if t, ok := byID[taskID]; ok {
+			t.Assignees = append(t.Assignees, a)
+		}
+	}
+	return rows.Err()
 }
 
 func (r *taskRepository) Delete(ctx context.Context, userID, id string) error {
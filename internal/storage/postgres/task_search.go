@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// TaskSearchResult adalah task hasil full-text search beserta skor dan potongan
+// teks yang disorot dengan <mark>...</mark>. Highlight sudah di-escape HTML
+// sehingga aman ditampilkan langsung sebagai HTML.
+type TaskSearchResult struct {
+	Task
+	Rank                 float32 `json:"rank"`
+	TitleHighlight       string  `json:"title_highlight"`
+	DescriptionHighlight string  `json:"description_highlight,omitempty"`
+}
+
+// ts_headline menandai kata dengan karakter private-use agar teks bisa di-escape
+// dulu sebelum penanda diganti tag <mark>.
+const (
+	highlightStart = "\ue000"
+	highlightStop  = "\ue001"
+
+	headlineTitleOpts       = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", HighlightAll=true`
+	headlineDescriptionOpts = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" … "`
+)
+
+var highlightTags = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// highlightHTML meng-escape hasil ts_headline lalu mengganti penanda dengan <mark>.
+func highlightHTML(s string) string {
+	return highlightTags.Replace(html.EscapeString(s))
+}
+
+// Search mencari task yang terlihat oleh user dengan sintaks websearch_to_tsquery ("frasa",
+// OR, -kata) dan mengurutkan hasil berdasarkan ts_rank. Filter selain Query/Sort
+// tetap berlaku.
+func (r *taskRepository) Search(ctx context.Context, userID, query string, f TaskFilter, page PageRequest) ([]TaskSearchResult, *PageInfo, error) {
+	limit := page.limit()
+	info := &PageInfo{Limit: limit}
+	offset := 0
+	if page.Cursor != "" {
+		o, err := decodeOffsetCursor(page.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		offset = o
+	}
+
+	f.Query = ""
+	args := []any{userID, query}
+	where := []string{f.viewCondition(1), "search_vector @@ websearch_to_tsquery('simple', $2)"}
+	conds, args := f.where(args)
+	where = append(where, conds...)
+
+	if page.WithTotal {
+		var total int
+		q := `select count(*) from public.tasks` + whereClause(where)
+		if err := r.pool.QueryRow(ctx, q, args...).Scan(&total); err != nil {
+			return nil, nil, err
+		}
+		info.Total = &total
+	}
+
+	args = append(args, limit+1, offset)
+	q := fmt.Sprintf(`select %s,
+               ts_rank(search_vector, websearch_to_tsquery('simple', $2)) as rank,
+               ts_headline('simple', title, websearch_to_tsquery('simple', $2), '%s'),
+               ts_headline('simple', coalesce(description, ''), websearch_to_tsquery('simple', $2), '%s')
+               from public.tasks%s
+               order by rank desc, id limit $%d offset $%d`,
+		taskColumns, headlineTitleOpts, headlineDescriptionOpts, whereClause(where), len(args)-1, len(args))
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var results []TaskSearchResult
+	for rows.Next() {
+		var res TaskSearchResult
+		dest := append(taskScanDest(&res.Task), &res.Rank, &res.TitleHighlight, &res.DescriptionHighlight)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, err
+		}
+		res.TitleHighlight = highlightHTML(res.TitleHighlight)
+		res.DescriptionHighlight = highlightHTML(res.DescriptionHighlight)
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(results) > limit {
+		results = results[:limit]
+		info.NextCursor = offsetCursor{Offset: offset + limit}.encode()
+	}
+	if offset > 0 {
+		info.PrevCursor = offsetCursor{Offset: max(offset-limit, 0)}.encode()
+	}
+	ptrs := make([]*Task, len(results))
+	for i := range results {
+		ptrs[i] = &results[i].Task
+	}
+	if err := r.loadDetails(ctx, ptrs...); err != nil {
+		return nil, nil, err
+	}
+	return results, info, nil
+}
@@ -0,0 +1,19 @@
+package postgres
+
+import "testing"
+
+func TestHighlightHTML(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"rapat " + highlightStart + "mingguan" + highlightStop, "rapat <mark>mingguan</mark>"},
+		{`<script>alert("x")</script> ` + highlightStart + "bug" + highlightStop,
+			"&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <mark>bug</mark>"},
+		{"AT&T " + highlightStart + "<b>" + highlightStop, "AT&amp;T <mark>&lt;b&gt;</mark>"},
+		{"<mark>palsu</mark>", "&lt;mark&gt;palsu&lt;/mark&gt;"},
+	}
+	for _, tt := range tests {
+		if got := highlightHTML(tt.in); got != tt.want {
+			t.Errorf("highlightHTML(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
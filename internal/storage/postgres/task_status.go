@@ -0,0 +1,49 @@
+package postgres
+
+import "slices"
+
+type TaskStatus string
+
+const (
+	TaskStatusTodo       TaskStatus = "Todo"
+	TaskStatusInProgress TaskStatus = "In Progress"
+	TaskStatusBlocked    TaskStatus = "Blocked"
+	TaskStatusDone       TaskStatus = "Done"
+	TaskStatusCancelled  TaskStatus = "Cancelled"
+)
+
+// TaskStatuses adalah semua status yang valid, sesuai urutan alur kerja.
+var TaskStatuses = []TaskStatus{
+	TaskStatusTodo, TaskStatusInProgress, TaskStatusBlocked, TaskStatusDone, TaskStatusCancelled,
+}
+
+// taskTransitions adalah perpindahan status yang diizinkan. Done dan Cancelled
+// bisa dibuka kembali; task yang Blocked harus dibuka dulu sebelum Done.
+var taskTransitions = map[TaskStatus][]TaskStatus{
+	TaskStatusTodo:       {TaskStatusInProgress, TaskStatusBlocked, TaskStatusDone, TaskStatusCancelled},
+	TaskStatusInProgress: {TaskStatusTodo, TaskStatusBlocked, TaskStatusDone, TaskStatusCancelled},
+	TaskStatusBlocked:    {TaskStatusTodo, TaskStatusInProgress, TaskStatusCancelled},
+	TaskStatusDone:       {TaskStatusTodo, TaskStatusInProgress},
+	TaskStatusCancelled:  {TaskStatusTodo},
+}
+
+func (s TaskStatus) Valid() bool {
+	_, ok := taskTransitions[s]
+	return ok
+}
+
+// CanTransitionTo melaporkan apakah task boleh pindah dari s ke next.
+// Tetap di status yang sama selalu diizinkan.
+func (s TaskStatus) CanTransitionTo(next TaskStatus) bool {
+	return s == next || slices.Contains(taskTransitions[s], next)
+}
+
+// Transitions mengembalikan status tujuan yang diizinkan dari s.
+func (s TaskStatus) Transitions() []TaskStatus {
+	return taskTransitions[s]
+}
+
+// Closed melaporkan apakah task sudah selesai atau dibatalkan.
+func (s TaskStatus) Closed() bool {
+	return s == TaskStatusDone || s == TaskStatusCancelled
+}
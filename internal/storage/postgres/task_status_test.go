@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTaskStatusTransitions(t *testing.T) {
+	const (
+		todo      = TaskStatusTodo
+		progress  = TaskStatusInProgress
+		blocked   = TaskStatusBlocked
+		done      = TaskStatusDone
+		cancelled = TaskStatusCancelled
+	)
+	// allowed[from][to]; edge yang tidak tercantum dilarang
+	allowed := map[TaskStatus]map[TaskStatus]bool{
+		todo:      {todo: true, progress: true, blocked: true, done: true, cancelled: true},
+		progress:  {todo: true, progress: true, blocked: true, done: true, cancelled: true},
+		blocked:   {todo: true, progress: true, blocked: true, cancelled: true},
+		done:      {todo: true, progress: true, done: true},
+		cancelled: {todo: true, cancelled: true},
+	}
+	for _, from := range TaskStatuses {
+		for _, to := range TaskStatuses {
+			want := allowed[from][to]
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s -> %s: CanTransitionTo = %v, want %v", from, to, got, want)
+			}
+			// Transitions tidak mencantumkan status yang sama
+			if listed := slices.Contains(from.Transitions(), to); listed != (want && from != to) {
+				t.Errorf("%s -> %s: tercantum di Transitions = %v", from, to, listed)
+			}
+		}
+	}
+}
+
+func TestTaskStatusInvalid(t *testing.T) {
+	for _, s := range []TaskStatus{"", "todo", "Archived"} {
+		if s.Valid() {
+			t.Errorf("%q dianggap valid", s)
+		}
+		if s.CanTransitionTo(TaskStatusTodo) || TaskStatusTodo.CanTransitionTo(s) {
+			t.Errorf("transisi dari/ke %q diizinkan", s)
+		}
+	}
+	for _, s := range TaskStatuses {
+		if !s.Valid() {
+			t.Errorf("%q dianggap tidak valid", s)
+		}
+	}
+}
+
+func TestTaskStatusClosed(t *testing.T) {
+	for _, s := range TaskStatuses {
+		want := s == TaskStatusDone || s == TaskStatusCancelled
+		if s.Closed() != want {
+			t.Errorf("%s.Closed() = %v, want %v", s, s.Closed(), want)
+		}
+	}
+}
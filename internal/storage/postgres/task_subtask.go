@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+)
+
+// MaxTaskDepth adalah jumlah level maksimum pohon subtask (task utama dihitung satu level).
+const MaxTaskDepth = 3
+
+var (
+	ErrTaskCycle   = errors.New("parent tidak boleh task itu sendiri atau subtask-nya")
+	ErrTaskTooDeep = errors.New("subtask melebihi kedalaman maksimum")
+)
+
+// TaskRef adalah ringkasan task terkait (subtask, dependency) di detail task.
+type TaskRef struct {
+	ID     string     `json:"id"`
+	Title  string     `json:"title"`
+	Status TaskStatus `json:"status"`
+}
+
+// CheckParent memastikan task id (kosong untuk task baru) boleh menjadi subtask
+// parentID: parent bukan task itu sendiri atau turunannya, dan kedalaman pohon
+// setelah dipindah tidak melebihi MaxTaskDepth.
+func (r *taskRepository) CheckParent(ctx context.Context, id, parentID string) error {
+	// ancestors berisi parent dan semua leluhurnya beserta levelnya dari parent
+	const up = `with recursive ancestors (id, parent_id, depth) as (
+                  select id, parent_id, 1 from public.tasks where id = $2
+                  union all
+                  select t.id, t.parent_id, a.depth + 1
+                  from public.tasks t join ancestors a on t.id = a.parent_id
+                  where a.depth <= $3
+                )
+                select coalesce(bool_or(id::text = $1), false), coalesce(max(depth), 0) from ancestors`
+	var cycle bool
+	var depth int
+	if err := r.pool.QueryRow(ctx, up, id, parentID, MaxTaskDepth).Scan(&cycle, &depth); err != nil {
+		return err
+	}
+	if cycle {
+		return ErrTaskCycle
+	}
+	// tinggi subtree task yang dipindah (1 bila tidak punya subtask / task baru)
+	height := 1
+	if id != "" {
+		const down = `with recursive descendants (id, depth) as (
+                        select id, 1 from public.tasks where id = $1
+                        union all
+                        select t.id, d.depth + 1
+                        from public.tasks t join descendants d on t.parent_id = d.id
+                        where d.depth <= $2
+                      )
+                      select coalesce(max(depth), 1) from descendants`
+		if err := r.pool.QueryRow(ctx, down, id, MaxTaskDepth).Scan(&height); err != nil {
+			return err
+		}
+	}
+	if depth+height > MaxTaskDepth {
+		return ErrTaskTooDeep
+	}
+	return nil
+}
+
+// loadBreakdown mengisi Subtasks, Checklist, dependency dan Progress untuk detail task.
+// Subtasks dan dependency hanya berisi task yang terlihat oleh viewerID (kosong =
+// semua, untuk Admin). Progress dihitung dari semua subtask langsung (Done =
+// selesai, Cancelled tidak dihitung) dan item checklist; nil bila keduanya kosong.
+func (r *taskRepository) loadBreakdown(ctx context.Context, t *Task, viewerID string) error {
+	var err error
+	qs := `select id, title, status from public.tasks where parent_id = $1`
+	args := []any{t.ID}
+	if viewerID != "" {
+		qs += ` and ` + visibleTo(2)
+		args = append(args, viewerID)
+	}
+	if t.Subtasks, err = r.listTaskRefs(ctx, qs+` order by position, id`, args...); err != nil {
+		return err
+	}
+	if err := r.loadDependencies(ctx, t, viewerID); err != nil {
+		return err
+	}
+	if t.Checklist, err = listChecklist(ctx, r.pool, t.ID); err != nil {
+		return err
+	}
+
+	var done, total int
+	const qp = `select count(*) filter (where status = 'Done'), count(*) filter (where status <> 'Cancelled')
+                from public.tasks where parent_id = $1`
+	if err := r.pool.QueryRow(ctx, qp, t.ID).Scan(&done, &total); err != nil {
+		return err
+	}
+	for _, item := range t.Checklist {
+		total++
+		if item.Done {
+			done++
+		}
+	}
+	t.Progress = nil
+	if total > 0 {
+		p := done * 100 / total
+		t.Progress = &p
+	}
+	return nil
+}
+
+// listTaskRefs menjalankan query yang memilih id, title dan status task.
+func (r *taskRepository) listTaskRefs(ctx context.Context, q string, args ...any) ([]TaskRef, error) {
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	refs := []TaskRef{}
+	for rows.Next() {
+		var ref TaskRef
+		if err := rows.Scan(&ref.ID, &ref.Title, &ref.Status); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
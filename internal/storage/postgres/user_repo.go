@@ -3,6 +3,8 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -17,18 +19,39 @@ const (
 )
 
 type User struct {
-	ID           string
-	Name         string
-	Email        string
-	PasswordHash string
-	Role         UserRole
-	Department   *string
-	CreatedAt    time.Time
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Email        string   `json:"email"`
+	PasswordHash string   `json:"-"`
+	Role         UserRole `json:"role"`
+	Department   *string  `json:"department,omitempty"`
+	IsActive     bool     `json:"is_active"`
+	TokenVersion int      `json:"-"`
+	// EmailVerifiedAt nil berarti email belum diverifikasi.
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// UserFilter membatasi hasil List. Field kosong/nil berarti tanpa filter.
+type UserFilter struct {
+	Query      string
+	Role       *UserRole
+	Department *string
+	Active     *bool
 }
 
 type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	GetByEmail(ctx context.Context, email string) (*User, error)
+	GetByID(ctx context.Context, id string) (*User, error)
+	IncrementTokenVersion(ctx context.Context, id string) error
+	List(ctx context.Context, f UserFilter, limit, offset int) ([]User, int, error)
+	Update(ctx context.Context, user *User) error
+	UpdatePassword(ctx context.Context, id, passwordHash string) error
+	SetActive(ctx context.Context, id string, active bool) error
+	MarkEmailVerified(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
 }
 
 type userRepository struct {
@@ -39,11 +62,24 @@ func NewUserRepository(pool *pgxpool.Pool) UserRepository {
 	return &userRepository{pool: pool}
 }
 
+const userColumns = `id, name, email, password_hash, role, department, is_active, token_version, email_verified_at, created_at, updated_at`
+
+func scanUser(row pgx.Row) (*User, error) {
+	var u User
+	if err := row.Scan(&u.ID, &u.Name, &u.Email, &u.PasswordHash, &u.Role, &u.Department, &u.IsActive, &u.TokenVersion, &u.EmailVerifiedAt, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
 func (r *userRepository) Create(ctx context.Context, user *User) error {
 	query := `
         insert into public.users (name, email, password_hash, role, department)
         values ($1, $2, $3, $4, $5)
-        returning id, created_at
+        returning id, is_active, created_at, updated_at
     `
 
 	return r.pool.QueryRow(ctx, query,
@@ -52,24 +88,130 @@ func (r *userRepository) Create(ctx context.Context, user *User) error {
 		user.PasswordHash,
 		user.Role,
 		user.Department,
-	).Scan(&user.ID, &user.CreatedAt)
+	).Scan(&user.ID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt)
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	query := `select ` + userColumns + ` from public.users where email = $1 limit 1`
+	return scanUser(r.pool.QueryRow(ctx, query, email))
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id string) (*User, error) {
+	query := `select ` + userColumns + ` from public.users where id = $1 limit 1`
+	return scanUser(r.pool.QueryRow(ctx, query, id))
+}
+
+// IncrementTokenVersion membatalkan semua access token user yang sudah terbit.
+func (r *userRepository) IncrementTokenVersion(ctx context.Context, id string) error {
+	query := `update public.users set token_version = token_version + 1 where id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
+	return err
+}
+
+// where mengembalikan kondisi filter beserta argumennya. Query dicari sebagai
+// substring literal: % dan _ di dalamnya tidak dianggap wildcard.
+func (f UserFilter) where() ([]string, []any) {
+	var where []string
+	var args []any
+	if f.Query != "" {
+		args = append(args, "%"+escapeLike(f.Query)+"%")
+		where = append(where, fmt.Sprintf(`(name ilike $%[1]d escape '\' or email ilike $%[1]d escape '\')`, len(args)))
+	}
+	if f.Role != nil {
+		args = append(args, *f.Role)
+		where = append(where, fmt.Sprintf("role = $%d", len(args)))
+	}
+	if f.Department != nil {
+		args = append(args, *f.Department)
+		where = append(where, fmt.Sprintf("department = $%d", len(args)))
+	}
+	if f.Active != nil {
+		args = append(args, *f.Active)
+		where = append(where, fmt.Sprintf("is_active = $%d", len(args)))
+	}
+	return where, args
+}
+
+// List mencari user dengan filter dan paginasi, sekaligus mengembalikan total data.
+func (r *userRepository) List(ctx context.Context, f UserFilter, limit, offset int) ([]User, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	where, args := f.where()
+	cond := ""
+	if len(where) > 0 {
+		cond = " where " + strings.Join(where, " and ")
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `select count(*) from public.users`+cond, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`select %s from public.users%s order by created_at desc limit $%d offset $%d`,
+		userColumns, cond, len(args)-1, len(args))
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var users []User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, *u)
+	}
+	return users, total, rows.Err()
+}
+
+// Update menyimpan name, role dan department user.
+func (r *userRepository) Update(ctx context.Context, user *User) error {
 	query := `
-        select id, name, email, password_hash, role, department, created_at
-        from public.users where email = $1 limit 1
+        update public.users set name = $1, role = $2, department = $3, updated_at = now()
+        where id = $4 returning updated_at
     `
+	return r.pool.QueryRow(ctx, query, user.Name, user.Role, user.Department, user.ID).Scan(&user.UpdatedAt)
+}
 
-	row := r.pool.QueryRow(ctx, query, email)
-	var u User
-	if err := row.Scan(&u.ID, &u.Name, &u.Email, &u.PasswordHash, &u.Role, &u.Department, &u.CreatedAt); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
+func (r *userRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	query := `update public.users set password_hash = $1, updated_at = now() where id = $2`
+	_, err := r.pool.Exec(ctx, query, passwordHash, id)
+	return err
+}
+
+func (r *userRepository) SetActive(ctx context.Context, id string, active bool) error {
+	query := `update public.users set is_active = $1, updated_at = now() where id = $2`
+	tag, err := r.pool.Exec(ctx, query, active, id)
+	if err != nil {
+		return err
 	}
-	return &u, nil
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id string) error {
+	query := `update public.users set email_verified_at = coalesce(email_verified_at, now()), updated_at = now() where id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
+	return err
+}
+
+func (r *userRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `delete from public.users where id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
 }
 
 // end
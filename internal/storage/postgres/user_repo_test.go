@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUserFilterWhere(t *testing.T) {
+	role := RoleAdmin
+	active := true
+	tests := []struct {
+		name  string
+		f     UserFilter
+		where []string
+		args  []any
+	}{
+		{"kosong", UserFilter{}, nil, nil},
+		{"query biasa", UserFilter{Query: "budi"},
+			[]string{`(name ilike $1 escape '\' or email ilike $1 escape '\')`}, []any{"%budi%"}},
+		{"wildcard di query di-escape", UserFilter{Query: `50%_a\b`},
+			[]string{`(name ilike $1 escape '\' or email ilike $1 escape '\')`}, []any{`%50\%\_a\\b%`}},
+		{"query dan filter lain", UserFilter{Query: "a", Role: &role, Active: &active},
+			[]string{`(name ilike $1 escape '\' or email ilike $1 escape '\')`, "role = $2", "is_active = $3"},
+			[]any{"%a%", role, true}},
+	}
+	for _, tt := range tests {
+		where, args := tt.f.where()
+		if !reflect.DeepEqual(where, tt.where) {
+			t.Errorf("%s: where = %q, want %q", tt.name, where, tt.where)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Tujuan token sekali pakai yang dikirim lewat email.
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// UserToken adalah token sekali pakai (disimpan sebagai hash) yang terikat ke user.
+type UserToken struct {
+	ID        string
+	UserID    string
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, t *UserToken) error
+	Get(ctx context.Context, purpose, tokenHash string) (*UserToken, error)
+	Consume(ctx context.Context, purpose, tokenHash string) (*UserToken, error)
+	DeleteForUser(ctx context.Context, userID, purpose string) error
+}
+
+type userTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewUserTokenRepository(pool *pgxpool.Pool) UserTokenRepository {
+	return &userTokenRepository{pool: pool}
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, t *UserToken) error {
+	const q = `insert into public.user_tokens (user_id, purpose, token_hash, expires_at)
+               values ($1, $2, $3, $4)
+               returning id, created_at`
+	return r.pool.QueryRow(ctx, q, t.UserID, t.Purpose, t.TokenHash, t.ExpiresAt).Scan(&t.ID, &t.CreatedAt)
+}
+
+// Get mengembalikan token yang masih berlaku tanpa memakainya; nil bila token
+// tidak ada, sudah dipakai, atau kedaluwarsa.
+func (r *userTokenRepository) Get(ctx context.Context, purpose, tokenHash string) (*UserToken, error) {
+	const q = `select id, user_id, purpose, token_hash, expires_at, used_at, created_at
+               from public.user_tokens
+               where purpose=$1 and token_hash=$2 and used_at is null and expires_at > now()`
+	var t UserToken
+	if err := r.pool.QueryRow(ctx, q, purpose, tokenHash).Scan(
+		&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Consume menandai token terpakai secara atomik. Mengembalikan nil bila token
+// tidak ada, sudah dipakai, atau kedaluwarsa.
+func (r *userTokenRepository) Consume(ctx context.Context, purpose, tokenHash string) (*UserToken, error) {
+	const q = `update public.user_tokens set used_at=now()
+               where purpose=$1 and token_hash=$2 and used_at is null and expires_at > now()
+               returning id, user_id, purpose, token_hash, expires_at, used_at, created_at`
+	var t UserToken
+	if err := r.pool.QueryRow(ctx, q, purpose, tokenHash).Scan(
+		&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteForUser menghapus token yang belum terpakai, mis. saat token baru diterbitkan.
+func (r *userTokenRepository) DeleteForUser(ctx context.Context, userID, purpose string) error {
+	const q = `delete from public.user_tokens where user_id=$1 and purpose=$2 and used_at is null`
+	_, err := r.pool.Exec(ctx, q, userID, purpose)
+	return err
+}